@@ -0,0 +1,167 @@
+package peek
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/main-kube/util/safe"
+)
+
+type action struct {
+	key byte
+	fn  func(wa *Watcher) error
+}
+
+var actions = safe.SortedMap[string, *action]{}
+
+// commands are palette commands that take an argument, e.g. 'filter error'.
+var commands = safe.SortedMap[string, func(wa *Watcher, arg string) error]{}
+
+func command(name string, fn func(wa *Watcher, arg string) error) {
+	commands.Set(name, fn)
+}
+
+// modeKeys are handled by the dashboard before hotkeys: 'e' edits Vars, ':' opens the palette
+// and 'c' and 's' resume a breakpoint.
+const modeKeys = "e:cs"
+
+// Action registers a command that can be run from the dashboard in interactive mode,
+// either with its hotkey or by typing its name in the command palette opened with ':'.
+// Pass 0 as key to make it palette only. Action panics if key is already taken,
+// by e : c s, which the dashboard uses, a built in hotkey like '/', 'g' or 'P' or another Action.
+// The result is printed in the log pane, a panic in fn is recovered and logged too.
+func Action(name string, key byte, fn func() error) {
+	if key != 0 && keyTaken(name, key) {
+		panic(fmt.Sprintf("peek: Action %q: key %q is already used", name, key))
+	}
+	addAction(name, key, func(wa *Watcher) error { return fn() })
+}
+
+// addAction registers an Action that reports its progress in the log pane, used by the built in ones.
+func addAction(name string, key byte, fn func(wa *Watcher) error) {
+	actions.Set(name, &action{key: key, fn: fn})
+	if key != 0 {
+		keys.Set(key, func(wa *Watcher) { wa.runAction(name) })
+	}
+}
+
+// keyTaken reports if key is used by the dashboard or anything but the action called name.
+func keyTaken(name string, key byte) bool {
+	if strings.IndexByte(modeKeys, key) >= 0 {
+		return true
+	}
+	if a := actions.Get(name); a != nil && a.key == key {
+		return false
+	}
+	return keys.Get(key) != nil
+}
+
+// runAction runs the action in the background and logs how it went.
+func (wa *Watcher) runAction(name string) {
+	a := actions.Get(name)
+	if a == nil {
+		wa.log(fmt.Sprintf("> %s: no such action", name))
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				wa.log(fmt.Sprintf("> %s: panic: %v", name, r))
+			}
+		}()
+		start := time.Now()
+		if err := a.fn(wa); err != nil {
+			wa.log(fmt.Sprintf("> %s: error: %v", name, err))
+			return
+		}
+		wa.log(fmt.Sprintf("> %s: ok (%s)", name, time.Since(start).Round(time.Millisecond)))
+	}()
+}
+
+// matchActions returns names of actions starting with prefix.
+func matchActions(prefix string) (names []string) {
+	for _, name := range actions.Keys() {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// paletteKey handles the command palette, returns false if the key wasn't for it.
+func (wa *Watcher) paletteKey(k byte) bool {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	switch wa.mode {
+	case modeNormal:
+		if k != ':' {
+			return false
+		}
+		wa.mode = modePalette
+		wa.input = ""
+
+	case modePalette:
+		switch k {
+		case '\r', '\n':
+			wa.mode = modeNormal
+			name := wa.input
+			wa.mu.Unlock()
+			if cmd, arg, _ := strings.Cut(name, " "); commands.Exists(cmd) {
+				if err := commands.Get(cmd)(wa, arg); err != nil {
+					wa.log(fmt.Sprintf("> %s: error: %v", name, err))
+				}
+			} else {
+				// a unique prefix is enough
+				if m := matchActions(name); len(m) == 1 {
+					name = m[0]
+				}
+				wa.runAction(name)
+			}
+			wa.mu.Lock()
+		case '\t':
+			if m := matchActions(wa.input); len(m) == 1 {
+				wa.input = m[0]
+			}
+		case 0x1b:
+			wa.mode = modeNormal
+		case 0x7f, '\b':
+			if len(wa.input) > 0 {
+				wa.input = wa.input[:len(wa.input)-1]
+			}
+		default:
+			if k >= ' ' {
+				wa.input += string(k)
+			}
+		}
+
+	default:
+		return false
+	}
+	return true
+}
+
+// openPalette opens the command palette with input already typed in.
+func (wa *Watcher) openPalette(input string) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.mode = modePalette
+	wa.input = input
+}
+
+// paletteStatus returns the palette prompt with the matching actions and commands.
+func (wa *Watcher) paletteStatus() string {
+	var hints []string
+	for _, name := range commands.Keys() {
+		if strings.HasPrefix(name, wa.input) {
+			hints = append(hints, name+" …")
+		}
+	}
+	for _, name := range matchActions(wa.input) {
+		if a := actions.Get(name); a != nil && a.key != 0 {
+			name = fmt.Sprintf("%s [%c]", name, a.key)
+		}
+		hints = append(hints, name)
+	}
+	return fmt.Sprintf(":%s_   %s", wa.input, strings.Join(hints, ", "))
+}
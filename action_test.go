@@ -0,0 +1,57 @@
+package peek
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActionPanicIsLogged(t *testing.T) {
+	wa := &Watcher{c: make(chan struct{}, 1)}
+	Action("test panic", 0, func() error { panic("boom") })
+	defer actions.Delete("test panic")
+	wa.runAction("test panic")
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		wa.mu.Lock()
+		lines := wa.lines
+		wa.mu.Unlock()
+		if len(lines) > 0 {
+			if want := "> test panic: panic: boom"; lines[0].text != want {
+				t.Fatalf("logged %q, want %q", lines[0].text, want)
+			}
+			return
+		}
+	}
+	t.Fatal("nothing logged")
+}
+
+func TestActionTakenKey(t *testing.T) {
+	for _, k := range []byte("e:cs/gPHT") {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(r.(string), "already used") {
+					t.Errorf("Action with key %q: recovered %v, want a key already used panic", k, r)
+				}
+			}()
+			Action("test taken", k, func() error { return nil })
+		}()
+	}
+	if actions.Get("test taken") != nil {
+		t.Fatal("action with a taken key was registered")
+	}
+
+	Action("test key", 'Z', func() error { return nil })
+	defer func() {
+		actions.Delete("test key")
+		keys.Delete('Z')
+	}()
+	// registering the same action again is fine, taking its key for another one isn't
+	Action("test key", 'Z', func() error { return nil })
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("another Action took the key of an existing one")
+		}
+	}()
+	Action("test other", 'Z', func() error { return nil })
+}
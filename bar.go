@@ -0,0 +1,77 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Number is any integer or float type.
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Progress adds a progress bar of done out of total with percentage, throughput and ETA.
+// The bar is as wide as the terminal allows.
+func Progress[T Number](desc string, done *T, total T, opts ...EntryOption) {
+	t := float64(total)
+	Var(desc, done, append(opts, optionFunc(func(e *entry) {
+		e.draw = func(e *entry, v any, width int) string {
+			f, _ := toFloat(v)
+			stats := fmt.Sprintf(" %s/%s %5.1f%%", e.text(v), e.text(total), percent(f, 0, t))
+			if e.rate > 0 {
+				stats += fmt.Sprintf(" %s/s", si(e.rate))
+				if f < t {
+					eta := time.Duration((t - f) / e.rate * float64(time.Second))
+					stats += fmt.Sprintf(" eta %s", eta.Round(time.Second))
+				}
+			}
+			return bar(percent(f, 0, t)/100, width-len(stats)) + stats
+		}
+	}))...)
+}
+
+// Gauge adds a horizontal bar showing where v is between min and max.
+func Gauge[T Number](desc string, v *T, min, max T, opts ...EntryOption) {
+	lo, hi := float64(min), float64(max)
+	Var(desc, v, append(opts, optionFunc(func(e *entry) {
+		e.draw = func(e *entry, v any, width int) string {
+			f, _ := toFloat(v)
+			text := " " + e.text(v)
+			return bar(percent(f, lo, hi)/100, width-len(text)) + text
+		}
+	}))...)
+}
+
+// percent returns where f is between lo and hi, clamped to 0-100.
+func percent(f, lo, hi float64) float64 {
+	if hi <= lo {
+		return 0
+	}
+	return math.Max(0, math.Min(100, (f-lo)/(hi-lo)*100))
+}
+
+var partialBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// bar draws frac (0-1) as a bar width cells wide including the brackets.
+func bar(frac float64, width int) string {
+	width -= 2
+	if width < 10 {
+		width = 10
+	}
+	cells := frac * float64(width)
+	full := int(cells)
+	part := int((cells - float64(full)) * 8)
+	s := strings.Repeat("█", full)
+	if full < width {
+		s += partialBlocks[part]
+		if part > 0 {
+			full++
+		}
+		s += strings.Repeat("░", width-full)
+	}
+	return "[" + s + "]"
+}
@@ -0,0 +1,46 @@
+package peek
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPercent(t *testing.T) {
+	for _, tc := range []struct {
+		f, lo, hi, want float64
+	}{
+		{5, 0, 10, 50},
+		{-5, 0, 10, 0},
+		{15, 0, 10, 100},
+		{15, 10, 20, 50},
+		{1, 1, 1, 0},
+		{1, 2, 1, 0},
+	} {
+		if got := percent(tc.f, tc.lo, tc.hi); got != tc.want {
+			t.Errorf("percent(%v, %v, %v) = %v, want %v", tc.f, tc.lo, tc.hi, got, tc.want)
+		}
+	}
+}
+
+func TestBar(t *testing.T) {
+	for _, tc := range []struct {
+		frac  float64
+		width int
+		want  string
+	}{
+		{0, 12, "[░░░░░░░░░░]"},
+		{1, 12, "[██████████]"},
+		{0.5, 12, "[█████░░░░░]"},
+		{0.55, 12, "[█████▌░░░░]"},
+		{0.5, 0, "[█████░░░░░]"},
+		{0.25, 22, "[█████░░░░░░░░░░░░░░░]"},
+	} {
+		got := bar(tc.frac, tc.width)
+		if got != tc.want {
+			t.Errorf("bar(%v, %d) = %q, want %q", tc.frac, tc.width, got, tc.want)
+		}
+		if w := utf8.RuneCountInString(got); tc.width >= 12 && w != tc.width {
+			t.Errorf("bar(%v, %d) is %d cells wide", tc.frac, tc.width, w)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package peek
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type breakpoint struct {
+	desc string
+	pred func(v any) bool
+	hit  bool
+}
+
+var brk = struct {
+	sync.Mutex
+	cond   *sync.Cond
+	points []*breakpoint
+	// armed is non zero when there is anything for Checkpoint to do
+	armed    int32
+	disabled bool
+	paused   bool
+	reason   string
+	step     bool
+}{}
+
+func init() {
+	brk.cond = sync.NewCond(&brk.Mutex)
+}
+
+// BreakWhen pauses the program when predicate returns true for the value of the entry registered as desc.
+// The program only stops in Checkpoint, put it in the loops you want to pause.
+// Funcs aren't called by Checkpoint, their value from the last render is checked.
+// Vars guarded with Locked are skipped while their lock is held, or always if it has no TryLock method.
+// Press 'c' to continue or 's' to let a single Checkpoint through.
+// Interactive mode is turned on automatically when a breakpoint is registered, see Watcher.Close.
+func BreakWhen(desc string, predicate func(v any) bool) {
+	brk.Lock()
+	defer brk.Unlock()
+	brk.points = append(brk.points, &breakpoint{desc: desc, pred: predicate})
+	if !brk.disabled {
+		atomic.StoreInt32(&brk.armed, 1)
+	}
+}
+
+// disableBreakpoints makes Checkpoint a no-op for good, used when there is no dashboard to resume from.
+func disableBreakpoints() {
+	brk.Lock()
+	defer brk.Unlock()
+	brk.disabled = true
+	atomic.StoreInt32(&brk.armed, 0)
+}
+
+// Checkpoint checks the breakpoints and blocks while the program is paused.
+// It's cheap when no breakpoints are registered.
+func Checkpoint() {
+	if atomic.LoadInt32(&brk.armed) == 0 {
+		return
+	}
+	brk.Lock()
+	defer brk.Unlock()
+	if !brk.paused {
+		for _, p := range brk.points {
+			e := vars.Get(p.desc)
+			if e == nil {
+				e = funcs.Get(p.desc)
+			}
+			if e == nil {
+				continue
+			}
+			v, ok := e.checkpointValue()
+			if !ok {
+				continue
+			}
+			// only fire when the predicate starts to match, otherwise continue would stop right away
+			hit := p.pred(v)
+			if hit && !p.hit {
+				brk.paused = true
+				brk.reason = fmt.Sprintf("%s%v", p.desc, v)
+			}
+			p.hit = hit
+		}
+	}
+	for brk.paused && !brk.step {
+		brk.cond.Wait()
+	}
+	brk.step = false
+}
+
+// checkpointValue returns the value breakpoints check without blocking, false if there is none.
+// Funcs aren't run, Checkpoint is called in hot loops, the value from the last render is used.
+// The lock of a Locked Var is only tried, the caller of Checkpoint may be holding it.
+func (e *entry) checkpointValue() (any, bool) {
+	if e.probe != nil {
+		return e.probe.cached()
+	}
+	if e.locker == nil || e.read == nil {
+		return e.get(), true
+	}
+	l, ok := e.locker.(interface{ TryLock() bool })
+	if !ok || !l.TryLock() {
+		return nil, false
+	}
+	defer e.locker.Unlock()
+	return e.read(), true
+}
+
+// breakKey handles continue and step keys while paused.
+func breakKey(k byte) bool {
+	brk.Lock()
+	defer brk.Unlock()
+	if !brk.paused {
+		return false
+	}
+	switch k {
+	case 'c':
+		brk.paused = false
+	case 's':
+		brk.step = true
+	default:
+		return false
+	}
+	brk.cond.Broadcast()
+	return true
+}
+
+// breakStatus returns the banner shown while paused.
+func breakStatus() (string, bool) {
+	brk.Lock()
+	defer brk.Unlock()
+	return brk.reason, brk.paused
+}
+
+func hasBreakpoints() bool {
+	return atomic.LoadInt32(&brk.armed) != 0
+}
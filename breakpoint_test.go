@@ -0,0 +1,75 @@
+package peek
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckpointDoesNotCallFuncs(t *testing.T) {
+	var calls int32
+	Func("test checkpoint", func() any {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(time.Second)
+		return 1
+	})
+	defer funcs.Delete("test checkpoint")
+	BreakWhen("test checkpoint", func(v any) bool { return v.(int) > 1 })
+	defer func() {
+		brk.Lock()
+		brk.points = nil
+		atomic.StoreInt32(&brk.armed, 0)
+		brk.Unlock()
+	}()
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		Checkpoint()
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("100 checkpoints took %s", d)
+	}
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Fatalf("Func called %d times", n)
+	}
+}
+
+func TestCheckpointWithLockedVarHeld(t *testing.T) {
+	var mu sync.Mutex
+	n := 0
+	Var("test locked", &n, Locked(&mu))
+	defer vars.Delete("test locked")
+	var checks int32
+	BreakWhen("test locked", func(v any) bool {
+		atomic.AddInt32(&checks, 1)
+		return false
+	})
+	defer func() {
+		brk.Lock()
+		brk.points = nil
+		atomic.StoreInt32(&brk.armed, 0)
+		brk.Unlock()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mu.Lock()
+		n++
+		Checkpoint()
+		mu.Unlock()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Checkpoint deadlocked on the Var's lock")
+	}
+	if c := atomic.LoadInt32(&checks); c != 0 {
+		t.Fatalf("predicate ran %d times while the lock was held", c)
+	}
+	Checkpoint()
+	if c := atomic.LoadInt32(&checks); c != 1 {
+		t.Fatalf("predicate ran %d times with the lock free, want 1", c)
+	}
+}
@@ -0,0 +1,23 @@
+package peek
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DBStats adds the connection pool stats of db to the watcher, sampled once a second.
+// Entries are prefixed with name so a few pools can be watched at once.
+func DBStats(name string, db *sql.DB) {
+	stat := func(desc string, fn func(s sql.DBStats) any, opts ...EntryOption) {
+		Func(name+" "+desc+": ", func() any { return fn(db.Stats()) }, append(opts, Every(time.Second))...)
+	}
+	stat("open", func(s sql.DBStats) any { return s.OpenConnections }, Sparkline())
+	stat("max open", func(s sql.DBStats) any { return s.MaxOpenConnections })
+	stat("in use", func(s sql.DBStats) any { return s.InUse }, Sparkline())
+	stat("idle", func(s sql.DBStats) any { return s.Idle }, Sparkline())
+	stat("wait count", func(s sql.DBStats) any { return s.WaitCount }, ShowRate(), Sparkline())
+	stat("wait time", func(s sql.DBStats) any { return s.WaitDuration }, Duration(time.Millisecond))
+	stat("closed max idle", func(s sql.DBStats) any { return s.MaxIdleClosed }, ShowRate())
+	stat("closed max idle time", func(s sql.DBStats) any { return s.MaxIdleTimeClosed }, ShowRate())
+	stat("closed max lifetime", func(s sql.DBStats) any { return s.MaxLifetimeClosed }, ShowRate())
+}
@@ -0,0 +1,164 @@
+package peek
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+const (
+	modeNormal = iota
+	modeSelect
+	modeEdit
+	modePalette
+)
+
+// Locked makes peek hold l while reading or writing the variable.
+// Use the same lock your program uses to guard it.
+// Checkpoint only tries l, so it can be called while holding it, see BreakWhen.
+func Locked(l sync.Locker) EntryOption {
+	return optionFunc(func(e *entry) { e.locker = l })
+}
+
+// Validate is called with the parsed value before it's written back from the dashboard.
+// Returning an error rejects the edit.
+func Validate(fn func(v any) error) EntryOption {
+	return optionFunc(func(e *entry) { e.validate = fn })
+}
+
+// setter returns a function that parses s according to T and writes it to v.
+func setter[T constraints.Ordered](e *entry, v *T) func(s string) error {
+	return func(s string) error {
+		var x T
+		rv := reflect.ValueOf(&x).Elem()
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(s, 0, rv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			rv.SetInt(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			u, err := strconv.ParseUint(s, 0, rv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			rv.SetUint(u)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(s, rv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			rv.SetFloat(f)
+		case reflect.String:
+			rv.SetString(s)
+		default:
+			return fmt.Errorf("can't edit %s", rv.Type())
+		}
+		if e.validate != nil {
+			if err := e.validate(x); err != nil {
+				return err
+			}
+		}
+		if e.locker != nil {
+			e.locker.Lock()
+			defer e.locker.Unlock()
+		}
+		*v = x
+		return nil
+	}
+}
+
+// editKey handles keys in select and edit mode, returns false if the key wasn't for it.
+func (wa *Watcher) editKey(k byte) bool {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	switch wa.mode {
+	case modeNormal:
+		if k != 'e' || vars.Len() == 0 {
+			return false
+		}
+		wa.mode = modeSelect
+		wa.cursor = 0
+
+	case modeSelect:
+		n := vars.Len()
+		switch {
+		case wa.esc == 1 && k == '[':
+			wa.esc = 2
+			return true
+		case wa.esc == 2 && k == 'A', wa.esc == 0 && k == 'k':
+			wa.cursor = (wa.cursor + n - 1) % n
+		case wa.esc == 2 && k == 'B', wa.esc == 0 && k == 'j':
+			wa.cursor = (wa.cursor + 1) % n
+		case wa.esc == 0 && k == 0x1b:
+			wa.esc = 1
+			return true
+		case wa.esc == 0 && (k == '\r' || k == '\n'):
+			wa.mode = modeEdit
+			wa.input = ""
+		default:
+			wa.mode = modeNormal
+		}
+		wa.esc = 0
+
+	case modeEdit:
+		switch k {
+		case '\r', '\n':
+			wa.mode = modeNormal
+			descs := vars.Keys()
+			if wa.cursor >= len(descs) {
+				break
+			}
+			desc := descs[wa.cursor]
+			e := vars.Get(desc)
+			old := e.get()
+			input := wa.input
+			// log takes the lock too
+			wa.mu.Unlock()
+			if err := e.set(input); err != nil {
+				wa.log(fmt.Sprintf("~ can't set %s%q: %v", desc, input, err))
+			} else {
+				wa.log(fmt.Sprintf("~ set %s%v -> %v", desc, old, e.get()))
+			}
+			wa.mu.Lock()
+		case 0x1b:
+			wa.mode = modeNormal
+		case 0x7f, '\b':
+			if len(wa.input) > 0 {
+				wa.input = wa.input[:len(wa.input)-1]
+			}
+		default:
+			if k >= ' ' {
+				wa.input += string(k)
+			}
+		}
+
+	default:
+		return false
+	}
+	return true
+}
+
+// editStatus returns the prompt shown in interactive modes and the selected entry.
+func (wa *Watcher) editStatus() (prompt string, selected string) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	switch wa.mode {
+	case modeNormal:
+		return "", ""
+	case modePalette:
+		return wa.paletteStatus(), ""
+	}
+	descs := vars.Keys()
+	if wa.cursor < len(descs) {
+		selected = descs[wa.cursor]
+	}
+	if wa.mode == modeSelect {
+		return "select with j/k, enter to edit, q to quit", selected
+	}
+	return fmt.Sprintf("%s= %s_", selected, wa.input), selected
+}
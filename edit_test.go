@@ -0,0 +1,78 @@
+package peek
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetter(t *testing.T) {
+	t.Run("int8", func(t *testing.T) {
+		var v int8 = 1
+		set := setter(&entry{}, &v)
+		for _, tc := range []struct {
+			in   string
+			want int8
+			ok   bool
+		}{
+			{"42", 42, true},
+			{"-128", -128, true},
+			{"0x10", 16, true},
+			{"128", 16, false},
+			{"abc", 16, false},
+			{"", 16, false},
+		} {
+			err := set(tc.in)
+			if (err == nil) != tc.ok || v != tc.want {
+				t.Errorf("set(%q): v = %d, err = %v, want %d, ok %v", tc.in, v, err, tc.want, tc.ok)
+			}
+		}
+	})
+	t.Run("uint16", func(t *testing.T) {
+		var v uint16
+		set := setter(&entry{}, &v)
+		if err := set("65535"); err != nil || v != 65535 {
+			t.Errorf("set(65535): v = %d, err = %v", v, err)
+		}
+		if err := set("65536"); err == nil || v != 65535 {
+			t.Errorf("set(65536) overflowed: v = %d, err = %v", v, err)
+		}
+		if err := set("-1"); err == nil || v != 65535 {
+			t.Errorf("set(-1): v = %d, err = %v", v, err)
+		}
+	})
+	t.Run("float32", func(t *testing.T) {
+		var v float32
+		set := setter(&entry{}, &v)
+		if err := set("1.5"); err != nil || v != 1.5 {
+			t.Errorf("set(1.5): v = %v, err = %v", v, err)
+		}
+		if err := set("1e40"); err == nil || v != 1.5 {
+			t.Errorf("set(1e40) overflowed: v = %v, err = %v", v, err)
+		}
+	})
+	t.Run("string", func(t *testing.T) {
+		v := "a"
+		set := setter(&entry{}, &v)
+		if err := set("hello world"); err != nil || v != "hello world" {
+			t.Errorf("set: v = %q, err = %v", v, err)
+		}
+	})
+}
+
+func TestSetterValidate(t *testing.T) {
+	errNegative := errors.New("must not be negative")
+	v := 5
+	e := (&entry{}).with([]EntryOption{Validate(func(x any) error {
+		if x.(int) < 0 {
+			return errNegative
+		}
+		return nil
+	})})
+	set := setter(e, &v)
+	if err := set("-1"); err != errNegative || v != 5 {
+		t.Errorf("set(-1): v = %d, err = %v, want the validation error and no write", v, err)
+	}
+	if err := set("7"); err != nil || v != 7 {
+		t.Errorf("set(7): v = %d, err = %v", v, err)
+	}
+}
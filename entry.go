@@ -0,0 +1,140 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// entry is a single line on the dashboard, registered with Var or Func.
+type entry struct {
+	get   func() any
+	rules []*Rule
+
+	// read returns the value without taking locker, only Vars have it
+	read func() any
+	// set parses and writes a new value, only Vars have it
+	set      func(s string) error
+	locker   sync.Locker
+	validate func(v any) error
+
+	// probe guards Func callbacks, nil for Vars
+	probe *probe
+
+	// format overrides the default %v, see Format
+	format func(v any) string
+	// draw replaces the value with something that fills width cells, e.g. a progress bar
+	draw func(e *entry, v any, width int) string
+
+	showRate bool
+	hist     *history
+	warnFull time.Duration
+	sum      bool
+
+	// rate of change per second, refreshed roughly once a second,
+	// hasRate is false until the first full second of a numeric value
+	rate     float64
+	hasRate  bool
+	rateFrom float64
+	rateAt   time.Time
+}
+
+// EntryOption customises a single dashboard entry, see Var and Func.
+type EntryOption interface {
+	apply(e *entry)
+}
+
+type optionFunc func(e *entry)
+
+func (fn optionFunc) apply(e *entry) {
+	fn(e)
+}
+
+// with applies opts to the entry.
+func (e *entry) with(opts []EntryOption) *entry {
+	for _, o := range opts {
+		o.apply(e)
+	}
+	return e
+}
+
+// Sparkline draws the history of the value, sampled once a second, next to it.
+// Combined with ShowRate it draws the history of the rate instead.
+func Sparkline() EntryOption {
+	return optionFunc(func(e *entry) { e.hist = newHistory(sparkWidth) })
+}
+
+// ShowRate shows how fast the value changes per second next to it, useful for counters.
+func ShowRate() EntryOption {
+	return optionFunc(func(e *entry) { e.showRate = true })
+}
+
+func (e *entry) updateRate(v any, now time.Time) {
+	f, ok := toFloat(v)
+	if !ok {
+		e.rateAt, e.hasRate = time.Time{}, false
+		return
+	}
+	if e.rateAt.IsZero() {
+		e.rateFrom, e.rateAt = f, now
+		if e.hist != nil && !e.showRate {
+			e.hist.push(f)
+		}
+		return
+	}
+	if dt := now.Sub(e.rateAt); dt >= time.Second {
+		e.rate, e.hasRate = (f-e.rateFrom)/dt.Seconds(), true
+		e.rateFrom, e.rateAt = f, now
+		if e.hist != nil {
+			if e.showRate {
+				e.hist.push(e.rate)
+			} else {
+				e.hist.push(f)
+			}
+		}
+	}
+}
+
+// currentRate returns the rate, NaN until one has been computed or when the value isn't a number.
+func (e *entry) currentRate() float64 {
+	if !e.hasRate {
+		return math.NaN()
+	}
+	return e.rate
+}
+
+// extras returns the rate and sparkline shown after the value.
+func (e *entry) extras(wa *Watcher) string {
+	s := ""
+	if e.showRate {
+		s += fmt.Sprintf(" %s(%s/s)%s", wa.faintColour, si(e.rate), Reset)
+	}
+	if e.hist != nil {
+		s += fmt.Sprintf(" %s%s%s", wa.faintColour, sparkline(e.hist.values(), sparkWidth/2), Reset)
+	}
+	return s
+}
+
+// text formats the value for the dashboard.
+func (e *entry) text(v any) string {
+	if e.format != nil {
+		return e.format(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// toFloat converts any numeric value (or pointer to one) to float64.
+func toFloat(v any) (float64, bool) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -10,7 +10,7 @@ import (
 func main() {
 	idx := 0
 	timeStart := time.Now()
-	peak.Create(100 * time.Millisecond)
+	peak.Create(peak.Interval(100 * time.Millisecond))
 	peak.Var("idx: ", &idx)
 	peak.Var("xD: ", &idx)
 	peak.Var("dupa: ", &idx)
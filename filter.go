@@ -0,0 +1,159 @@
+package peek
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// highlight colours every match of re in the log pane.
+type highlight struct {
+	re     *regexp.Regexp
+	colour string
+}
+
+func init() {
+	command("filter", func(wa *Watcher, arg string) error { return wa.FilterLog(arg, wa.excludeSrc()) })
+	command("exclude", func(wa *Watcher, arg string) error { return wa.FilterLog(wa.includeSrc(), arg) })
+	command("highlight", func(wa *Watcher, arg string) error {
+		pattern, spec, _ := strings.Cut(arg, " ")
+		if spec = strings.TrimSpace(spec); spec == "" {
+			spec = "yellow bold"
+		}
+		wa.mu.Lock()
+		mode := wa.colourMode
+		wa.mu.Unlock()
+		return wa.HighlightLog(pattern, colour(spec, mode))
+	})
+	command("nohighlight", func(wa *Watcher, arg string) error {
+		wa.ClearHighlights()
+		return nil
+	})
+	keys.Set('/', func(wa *Watcher) { wa.openPalette("filter ") })
+}
+
+// FilterLog only shows lines in the log pane that match include and don't match exclude,
+// an empty pattern turns that filter off. The log file still gets every line.
+// In interactive mode use 'filter <regexp>' and 'exclude <regexp>' in the command palette, or press '/'.
+func (wa *Watcher) FilterLog(include, exclude string) error {
+	var in, ex *regexp.Regexp
+	var err error
+	if include != "" {
+		if in, err = regexp.Compile(include); err != nil {
+			return err
+		}
+	}
+	if exclude != "" {
+		if ex, err = regexp.Compile(exclude); err != nil {
+			return err
+		}
+	}
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.include, wa.exclude = in, ex
+	return nil
+}
+
+// HighlightLog colours the matches of pattern in the log pane.
+// In interactive mode use 'highlight <regexp> [colour]' and 'nohighlight' in the command palette,
+// the colour is written like in Theme.
+func (wa *Watcher) HighlightLog(pattern, colour string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.highlights = append(wa.highlights, highlight{re: re, colour: colour})
+	return nil
+}
+
+// ClearHighlights removes all highlight rules.
+func (wa *Watcher) ClearHighlights() {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.highlights = nil
+}
+
+func (wa *Watcher) includeSrc() string {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	if wa.include == nil {
+		return ""
+	}
+	return wa.include.String()
+}
+
+func (wa *Watcher) excludeSrc() string {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	if wa.exclude == nil {
+		return ""
+	}
+	return wa.exclude.String()
+}
+
+// shown reports if the line passes the filters, wa.mu must be held.
+// The filters see the text as it's shown, without escape sequences.
+func (wa *Watcher) shown(s string) bool {
+	if wa.include == nil && wa.exclude == nil {
+		return true
+	}
+	s = plain(s)
+	return (wa.include == nil || wa.include.MatchString(s)) &&
+		(wa.exclude == nil || !wa.exclude.MatchString(s))
+}
+
+// highlight colours the matches of the highlight rules, wa.mu must be held.
+// Only the text between escape sequences is matched, after a match the colours active before it are restored.
+func (wa *Watcher) highlight(s string) string {
+	for _, h := range wa.highlights {
+		s = eachText(s, func(text, active string) string {
+			return h.re.ReplaceAllStringFunc(text, func(m string) string {
+				return fmt.Sprintf("%s%s%s%s%s", h.colour, m, Reset, wa.logColour, active)
+			})
+		})
+	}
+	return s
+}
+
+// plain returns s without escape sequences.
+func plain(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		out.WriteByte(s[i])
+		i++
+	}
+	return out.String()
+}
+
+// eachText replaces every run of text between escape sequences in s with fn(text, active),
+// active being the SGR sequences in effect since the last reset. The escape sequences are kept.
+func eachText(s string, fn func(text, active string) string) string {
+	var out, active strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			seq := s[i : i+n]
+			switch {
+			case seq == Reset || seq == "\033[m":
+				active.Reset()
+			case isSGR(seq):
+				active.WriteString(seq)
+			}
+			out.WriteString(seq)
+			i += n
+			continue
+		}
+		j := i + 1
+		for j < len(s) && escapeLen(s[j:]) == 0 {
+			j++
+		}
+		out.WriteString(fn(s[i:j], active.String()))
+		i = j
+	}
+	return out.String()
+}
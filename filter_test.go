@@ -0,0 +1,57 @@
+package peek
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightSkipsEscapes(t *testing.T) {
+	wa := &Watcher{theme: DarkTheme, colourMode: TrueColour}
+	wa.applyTheme()
+	line := wa.prettyJSON(`{"level":"info","msg":"took 1s","n":1}`)
+	if err := wa.HighlightLog("1", Magenta); err != nil {
+		t.Fatal(err)
+	}
+	got := wa.highlight(line)
+	if plain(got) != plain(line) {
+		t.Fatalf("highlight changed the text: %q", plain(got))
+	}
+	if n := strings.Count(got, Magenta); n != 2 {
+		t.Fatalf("%d highlights in %q, want 2", n, got)
+	}
+	// every escape sequence of the line is still there, in one piece
+	for _, seq := range strings.Split(line, "\033")[1:] {
+		if !strings.Contains(got, "\033"+strings.SplitN(seq, "m", 2)[0]+"m") {
+			t.Fatalf("escape %q broken in %q", seq, got)
+		}
+	}
+}
+
+func TestHighlightRestoresColours(t *testing.T) {
+	wa := &Watcher{logColour: White}
+	wa.HighlightLog("b", Red)
+	got := wa.highlight(Green + "abc" + Reset + "b")
+	want := Green + "a" + Red + "b" + Reset + White + Green + "c" + Reset + Red + "b" + Reset + White
+	if got != want {
+		t.Fatalf("highlight = %q, want %q", got, want)
+	}
+}
+
+func TestFilterMatchesPlainText(t *testing.T) {
+	wa := &Watcher{}
+	if err := wa.FilterLog("^INFO", "secret$"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		s    string
+		want bool
+	}{
+		{GreenBold + "INFO" + Reset + " started", true},
+		{"WARN " + GreenBold + "INFO" + Reset, false},
+		{"INFO the " + Red + "secret" + Reset, false},
+	} {
+		if got := wa.shown(tc.s); got != tc.want {
+			t.Errorf("shown(%q) = %v, want %v", tc.s, got, tc.want)
+		}
+	}
+}
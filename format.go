@@ -0,0 +1,92 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Format sets a custom formatter for the entry value.
+func Format(fn func(v any) string) EntryOption {
+	return optionFunc(func(e *entry) { e.format = fn })
+}
+
+// Verb formats the value with a fmt verb, e.g. "%08.3f".
+// Values implementing fmt.Formatter get the verb as usual.
+func Verb(verb string) EntryOption {
+	return Format(func(v any) string { return fmt.Sprintf(verb, v) })
+}
+
+// Precision shows numbers with n decimal places, integers included.
+func Precision(n int) EntryOption {
+	return numeric(func(f float64) string { return fmt.Sprintf("%.*f", n, f) })
+}
+
+// Hex shows integers in hex.
+func Hex() EntryOption {
+	return Verb("%#x")
+}
+
+// Bytes shows the value as a human readable size, e.g. 1073741824 as 1.0 GiB.
+func Bytes() EntryOption {
+	return numeric(humanBytes)
+}
+
+// Duration shows the value as a time.Duration rounded to round, plain numbers are nanoseconds.
+func Duration(round time.Duration) EntryOption {
+	return numeric(func(f float64) string { return time.Duration(f).Round(round).String() })
+}
+
+// Percent shows a ratio as a percentage, e.g. 0.42 as 42.0%.
+func Percent() EntryOption {
+	return numeric(func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) })
+}
+
+// SI shows the value with an SI suffix and unit, e.g. SI("req/s") shows 12300 as 12.3k req/s.
+func SI(unit string) EntryOption {
+	if unit != "" {
+		unit = " " + unit
+	}
+	return numeric(func(f float64) string { return si(f) + unit })
+}
+
+// numeric wraps a float formatter, values that aren't numbers
+// or that format themselves with fmt.Formatter are printed as usual.
+func numeric(fn func(f float64) string) EntryOption {
+	return Format(func(v any) string {
+		if _, ok := v.(fmt.Formatter); ok {
+			return fmt.Sprintf("%v", v)
+		}
+		f, ok := toFloat(v)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
+		return fn(f)
+	})
+}
+
+func humanBytes(f float64) string {
+	const units = "KMGTPE"
+	if math.Abs(f) < 1024 {
+		return fmt.Sprintf("%.0f B", f)
+	}
+	i := -1
+	for math.Abs(f) >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %ciB", f, units[i])
+}
+
+func si(f float64) string {
+	const units = "kMGTPE"
+	if math.Abs(f) < 1000 {
+		return fmt.Sprintf("%.4g", f)
+	}
+	i := -1
+	for math.Abs(f) >= 1000 && i < len(units)-1 {
+		f /= 1000
+		i++
+	}
+	return fmt.Sprintf("%.1f%c", f, units[i])
+}
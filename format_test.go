@@ -0,0 +1,21 @@
+package peek
+
+import "testing"
+
+func TestPrecision(t *testing.T) {
+	e := (&entry{}).with([]EntryOption{Precision(2)})
+	for _, tc := range []struct {
+		v    any
+		want string
+	}{
+		{5, "5.00"},
+		{uint8(7), "7.00"},
+		{3.14159, "3.14"},
+		{float32(0.5), "0.50"},
+		{"text", "text"},
+	} {
+		if got := e.text(tc.v); got != tc.want {
+			t.Errorf("text(%#v) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+}
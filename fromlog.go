@@ -0,0 +1,87 @@
+package peek
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// logValue is the value FromLog extracted from the last matching line.
+type logValue struct {
+	re  *regexp.Regexp
+	mu  sync.Mutex
+	v   any
+	sum bool
+}
+
+var (
+	scannersMu sync.Mutex
+	scanners   []*logValue
+)
+
+// Sum makes FromLog add up the captured numbers instead of showing the last one,
+// e.g. "processed 123 items" lines become a running total shown with its rate.
+func Sum() EntryOption {
+	return optionFunc(func(e *entry) {
+		e.sum = true
+		e.showRate = true
+	})
+}
+
+// FromLog adds an entry whose value is taken from captured output.
+// Every line is matched against pattern and the last capture group of a match becomes the value,
+// numbers are parsed so rules, formatters and Sparkline work on them.
+func FromLog(desc, pattern string, opts ...EntryOption) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	lv := &logValue{re: re}
+	e := &entry{get: lv.get}
+	e.with(opts)
+	lv.sum = e.sum
+	if lv.sum {
+		lv.v = 0.0
+	}
+	funcs.Set(desc, e)
+
+	scannersMu.Lock()
+	scanners = append(scanners, lv)
+	scannersMu.Unlock()
+	return nil
+}
+
+func (lv *logValue) get() any {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	return lv.v
+}
+
+func (lv *logValue) scan(line string) {
+	m := lv.re.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+	s := m[len(m)-1]
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	f, err := strconv.ParseFloat(s, 64)
+	switch {
+	case lv.sum && err == nil:
+		lv.v = lv.v.(float64) + f
+	case lv.sum:
+	case err == nil:
+		lv.v = f
+	default:
+		lv.v = s
+	}
+}
+
+// scanLine feeds a captured line to every FromLog entry.
+func scanLine(line string) {
+	scannersMu.Lock()
+	defer scannersMu.Unlock()
+	for _, lv := range scanners {
+		lv.scan(line)
+	}
+}
@@ -0,0 +1,56 @@
+package peek
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestLogValueScan(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		pattern string
+		sum     bool
+		lines   []string
+		want    any
+	}{
+		{"last number", `took (\d+)ms`, false, []string{"took 12ms", "other", "took 30ms"}, 30.0},
+		{"last group wins", `(\w+)=(\d+)`, false, []string{"n=7"}, 7.0},
+		{"text", `state: (\w+)`, false, []string{"state: 1", "state: ready"}, "ready"},
+		{"no match", `took (\d+)ms`, false, []string{"nothing"}, nil},
+		{"sum", `processed (\S+) items`, true, []string{"processed 10 items", "processed 2.5 items", "processed 3 items"}, 15.5},
+		{"sum skips text", `processed (\S+) items`, true, []string{"processed 10 items", "processed some items"}, 10.0},
+		{"whole match without groups", `\d+`, false, []string{"got 42 of them"}, 42.0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lv := &logValue{re: regexp.MustCompile(tc.pattern), sum: tc.sum}
+			if tc.sum {
+				lv.v = 0.0
+			}
+			for _, l := range tc.lines {
+				lv.scan(l)
+			}
+			if got := lv.get(); got != tc.want {
+				t.Errorf("value = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFromLog(t *testing.T) {
+	if err := FromLog("test bad", "("); err == nil {
+		t.Fatal("FromLog accepted a bad pattern")
+	}
+	if err := FromLog("test sum", `done (\d+)`, Sum()); err != nil {
+		t.Fatal(err)
+	}
+	defer funcs.Delete("test sum")
+	e := funcs.Get("test sum")
+	if !e.showRate {
+		t.Error("Sum didn't turn on ShowRate")
+	}
+	scanLine("done 4")
+	scanLine("done 6")
+	if got := e.get(); got != 10.0 {
+		t.Errorf("value = %v, want 10", got)
+	}
+}
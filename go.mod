@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/main-kube/util v0.0.0-20220824130840-1ae10d265801
+	github.com/tidwall/gjson v1.14.0
 	golang.org/x/exp v0.0.0-20220713135740-79cabaa25d75
 	golang.org/x/sys v0.0.0-20211019181941-9d821ace8654
 )
@@ -12,7 +13,6 @@ require (
 	github.com/evanphx/json-patch v0.5.2 // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/tidwall/gjson v1.14.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
 	github.com/wI2L/jsondiff v0.2.0 // indirect
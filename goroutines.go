@@ -0,0 +1,185 @@
+package peek
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	keys.Set('g', func(wa *Watcher) { wa.open(newGoroutineView()) })
+	addAction("dump goroutines", 0, func(wa *Watcher) error {
+		path, err := saveGoroutines(allStacks())
+		if err == nil {
+			wa.log(fmt.Sprintf("goroutines saved to %s", path))
+		}
+		return err
+	})
+}
+
+// allStacks returns runtime.Stack of every goroutine, growing the buffer until it fits.
+func allStacks() []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// saveGoroutines writes the dump next to the log file.
+func saveGoroutines(dump []byte) (string, error) {
+	path := dumpPath(fmt.Sprintf("goroutines-%s.txt", time.Now().Format("20060102-150405")))
+	return path, os.WriteFile(path, dump, 0644)
+}
+
+// dumpPath returns a path for name in the same directory as the log file.
+func dumpPath(name string) string {
+	dir := logDir()
+	os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, name)
+}
+
+type stackGroup struct {
+	state string
+	stack []string
+	ids   []string
+}
+
+var (
+	goroutineHeader = regexp.MustCompile(`^goroutine (\d+) \[([^,\]]+)`)
+	// arguments, pc offsets and parent ids differ between otherwise identical stacks
+	frameArgs = regexp.MustCompile(`\([^()]*\)$`)
+	pcOffset  = regexp.MustCompile(` \+0x[0-9a-f]+$| in goroutine \d+$`)
+)
+
+// groupStacks groups goroutines with the same state and stack, biggest groups first.
+func groupStacks(dump []byte) []*stackGroup {
+	groups := map[string]*stackGroup{}
+	for _, g := range strings.Split(strings.TrimSpace(string(dump)), "\n\n") {
+		lines := strings.Split(g, "\n")
+		m := goroutineHeader.FindStringSubmatch(lines[0])
+		if m == nil {
+			continue
+		}
+		stack := make([]string, 0, len(lines)-1)
+		for _, l := range lines[1:] {
+			l = frameArgs.ReplaceAllString(l, "(...)")
+			stack = append(stack, pcOffset.ReplaceAllString(l, ""))
+		}
+		key := m[2] + "\n" + strings.Join(stack, "\n")
+		sg, ok := groups[key]
+		if !ok {
+			sg = &stackGroup{state: m[2], stack: stack}
+			groups[key] = sg
+		}
+		sg.ids = append(sg.ids, m[1])
+	}
+
+	out := make([]*stackGroup, 0, len(groups))
+	for _, sg := range groups {
+		out = append(out, sg)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].ids) != len(out[j].ids) {
+			return len(out[i].ids) > len(out[j].ids)
+		}
+		return out[i].stack[0] < out[j].stack[0]
+	})
+	return out
+}
+
+// goroutineView lists stack groups, the selected one is expanded.
+// key runs on the goroutine reading stdin and lines on the render one, mu guards the rest.
+type goroutineView struct {
+	mu     sync.Mutex
+	dump   []byte
+	groups []*stackGroup
+	cursor int
+	at     time.Time
+	status string
+}
+
+func newGoroutineView() *goroutineView {
+	v := &goroutineView{}
+	v.refresh()
+	return v
+}
+
+func (v *goroutineView) refresh() {
+	v.dump = allStacks()
+	v.groups = groupStacks(v.dump)
+	v.at = time.Now()
+	if v.cursor >= len(v.groups) {
+		v.cursor = 0
+	}
+}
+
+func (v *goroutineView) key(wa *Watcher, k byte) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.groups) == 0 {
+		return true
+	}
+	switch k {
+	case 'j':
+		v.cursor = (v.cursor + 1) % len(v.groups)
+	case 'k':
+		v.cursor = (v.cursor + len(v.groups) - 1) % len(v.groups)
+	case 'r':
+		v.refresh()
+	case 'w':
+		path, err := saveGoroutines(v.dump)
+		if err != nil {
+			v.status = err.Error()
+		} else {
+			v.status = "saved to " + path
+			wa.log(fmt.Sprintf("goroutines saved to %s", path))
+		}
+	case 'q', 0x1b:
+		return true
+	}
+	return false
+}
+
+func (v *goroutineView) lines(wa *Watcher, width, height int) []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	total := 0
+	for _, g := range v.groups {
+		total += len(g.ids)
+	}
+	out := []string{
+		fmt.Sprintf("%s%d goroutines in %d groups, captured %s ago  %s[j/k] move [r] refresh [w] save [q] back  %s%s",
+			wa.headerColour, total, len(v.groups), time.Since(v.at).Round(time.Second), wa.faintColour, v.status, Reset),
+	}
+	// keep the selected group on screen, its stack takes the rest
+	first := 0
+	if v.cursor > height/2 {
+		first = v.cursor - height/2
+	}
+	for i := first; i < len(v.groups) && len(out) < height; i++ {
+		g := v.groups[i]
+		line := fmt.Sprintf("%5d × [%s] %s", len(g.ids), g.state, g.stack[0])
+		if i != v.cursor {
+			out = append(out, wa.valueColour+line+Reset)
+			continue
+		}
+		out = append(out, wa.valueColour+"\033[7m"+line+Reset)
+		for _, l := range g.stack[1:] {
+			out = append(out, wa.faintColour+"      "+l+Reset)
+		}
+	}
+	if len(out) > height {
+		out = out[:height]
+	}
+	return out
+}
@@ -0,0 +1,26 @@
+package peek
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGoroutineViewConcurrent(t *testing.T) {
+	wa := &Watcher{}
+	v := newGoroutineView()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			v.key(wa, 'r')
+			v.key(wa, 'j')
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		if len(v.lines(wa, 80, 24)) == 0 {
+			t.Fatal("no lines")
+		}
+	}
+	wg.Wait()
+}
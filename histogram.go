@@ -0,0 +1,207 @@
+package peek
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Buckets are log-linear like in HDR histograms: exact below 32,
+// above that every power of two is split into 16 buckets, so values are within ~6%.
+const (
+	subBits  = 4
+	subCount = 1 << subBits
+	nBuckets = (64 - subBits) * subCount
+	nSlots   = 10
+)
+
+func bucketOf(v uint64) int {
+	if v < 2*subCount {
+		return int(v)
+	}
+	shift := bits.Len64(v) - subBits - 1
+	return (shift+1)*subCount + int(v>>shift) - subCount
+}
+
+// bucketBounds returns the lowest and highest value that fall into bucket i.
+func bucketBounds(i int) (uint64, uint64) {
+	if i < 2*subCount {
+		return uint64(i), uint64(i)
+	}
+	shift := i/subCount - 1
+	m := uint64(i%subCount + subCount)
+	return m << shift, (m+1)<<shift - 1
+}
+
+// hdr is a sliding window histogram of durations with bounded memory,
+// the window is split into nSlots slots which are reset as time moves on.
+type hdr struct {
+	mu     sync.Mutex
+	slot   time.Duration
+	counts [nSlots][nBuckets]uint32
+	epochs [nSlots]int64
+}
+
+func newHDR(window time.Duration) *hdr {
+	slot := window / nSlots
+	if slot <= 0 {
+		slot = time.Second
+	}
+	return &hdr{slot: slot}
+}
+
+func (h *hdr) observe(d time.Duration, now time.Time) {
+	if d < 0 {
+		d = 0
+	}
+	epoch := now.UnixNano() / int64(h.slot)
+	i := epoch % nSlots
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.epochs[i] != epoch {
+		h.counts[i] = [nBuckets]uint32{}
+		h.epochs[i] = epoch
+	}
+	h.counts[i][bucketOf(uint64(d))]++
+}
+
+// snapshot merges the slots that are still in the window.
+func (h *hdr) snapshot(now time.Time) *hdrSnapshot {
+	epoch := now.UnixNano() / int64(h.slot)
+	s := &hdrSnapshot{}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.counts {
+		if epoch-h.epochs[i] >= nSlots {
+			continue
+		}
+		for b, c := range h.counts[i] {
+			s.counts[b] += uint64(c)
+			s.total += uint64(c)
+		}
+	}
+	return s
+}
+
+type hdrSnapshot struct {
+	counts [nBuckets]uint64
+	total  uint64
+}
+
+// quantile returns the upper bound of the bucket holding quantile q.
+func (s *hdrSnapshot) quantile(q float64) time.Duration {
+	target := uint64(q*float64(s.total) + 0.5)
+	if target == 0 {
+		target = 1
+	}
+	var sum uint64
+	for i, c := range s.counts {
+		sum += c
+		if sum >= target && c > 0 {
+			_, hi := bucketBounds(i)
+			return time.Duration(hi)
+		}
+	}
+	return 0
+}
+
+func (s *hdrSnapshot) summary() string {
+	if s.total == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("n=%d p50 %s p95 %s p99 %s max %s", s.total,
+		roundDuration(s.quantile(0.5)), roundDuration(s.quantile(0.95)),
+		roundDuration(s.quantile(0.99)), roundDuration(s.quantile(1)))
+}
+
+// bars groups the non empty buckets into at most rows horizontal bars.
+func (s *hdrSnapshot) bars(rows, width int) []string {
+	lo, hi := -1, -1
+	for i, c := range s.counts {
+		if c > 0 {
+			if lo < 0 {
+				lo = i
+			}
+			hi = i
+		}
+	}
+	if lo < 0 {
+		return nil
+	}
+	per := (hi - lo + rows) / rows
+	var groups []uint64
+	var labels []string
+	var max uint64
+	for i := lo; i <= hi; i += per {
+		var sum uint64
+		end := i + per - 1
+		if end > hi {
+			end = hi
+		}
+		for b := i; b <= end; b++ {
+			sum += s.counts[b]
+		}
+		_, upper := bucketBounds(end)
+		groups = append(groups, sum)
+		labels = append(labels, "≤ "+roundDuration(time.Duration(upper)).String())
+		if sum > max {
+			max = sum
+		}
+	}
+	width -= 26
+	if width < 10 {
+		width = 10
+	}
+	out := make([]string, len(groups))
+	for i, c := range groups {
+		out[i] = fmt.Sprintf("%12s %s %d", labels[i], strings.Repeat("█", int(c*uint64(width)/max)), c)
+	}
+	return out
+}
+
+// roundDuration keeps 3 significant digits.
+func roundDuration(d time.Duration) time.Duration {
+	r := time.Duration(1)
+	for d/r >= 1000 {
+		r *= 10
+	}
+	return d.Round(r)
+}
+
+// Histogram records latencies and shows their percentiles and distribution in its own panel.
+// Only the samples from the last window are taken into account.
+type Histogram struct {
+	hdr    *hdr
+	window time.Duration
+}
+
+// NewHistogram creates a histogram over a sliding window, e.g. 10*time.Second, and adds its panel to the watcher.
+func NewHistogram(desc string, window time.Duration) *Histogram {
+	h := &Histogram{hdr: newHDR(window), window: window}
+	panels.Set(desc, &panel{lines: h.lines})
+	return h
+}
+
+// Observe records a duration.
+func (h *Histogram) Observe(d time.Duration) {
+	h.hdr.observe(d, time.Now())
+}
+
+// Start starts timing and returns a func that records the elapsed time, e.g.
+//
+//	defer h.Start()()
+func (h *Histogram) Start() func() {
+	start := time.Now()
+	return func() { h.Observe(time.Since(start)) }
+}
+
+func (h *Histogram) lines(wa *Watcher, width int) []string {
+	s := h.hdr.snapshot(time.Now())
+	out := []string{wa.row(fmt.Sprintf("last %s", h.window), s.summary(), "")}
+	for _, b := range s.bars(8, width) {
+		out = append(out, wa.valueColour+b)
+	}
+	return out
+}
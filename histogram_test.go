@@ -0,0 +1,51 @@
+package peek
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuckets(t *testing.T) {
+	for _, v := range []uint64{0, 1, 31, 32, 33, 47, 48, 1000, 123456789, 1 << 40, math.MaxInt64} {
+		i := bucketOf(v)
+		if i < 0 || i >= nBuckets {
+			t.Fatalf("bucketOf(%d) = %d, out of range", v, i)
+		}
+		lo, hi := bucketBounds(i)
+		if v < lo || v > hi {
+			t.Errorf("%d falls into bucket %d of [%d, %d]", v, i, lo, hi)
+		}
+		if v >= 2*subCount && float64(hi-lo) > float64(lo)/subCount {
+			t.Errorf("bucket %d of [%d, %d] is wider than 1/%d", i, lo, hi, subCount)
+		}
+	}
+	// buckets are contiguous
+	for i := 1; i < bucketOf(math.MaxInt64); i++ {
+		_, prevHi := bucketBounds(i - 1)
+		if lo, _ := bucketBounds(i); lo != prevHi+1 {
+			t.Fatalf("bucket %d starts at %d, previous ends at %d", i, lo, prevHi)
+		}
+	}
+}
+
+func TestHDRWindow(t *testing.T) {
+	h := newHDR(10 * time.Second)
+	now := time.Now()
+	for i := 1; i <= 100; i++ {
+		h.observe(time.Duration(i)*time.Millisecond, now)
+	}
+	s := h.snapshot(now)
+	if s.total != 100 {
+		t.Fatalf("total = %d, want 100", s.total)
+	}
+	if p50 := s.quantile(0.5); p50 < 50*time.Millisecond || p50 > 53*time.Millisecond {
+		t.Errorf("p50 = %s, want ~50ms", p50)
+	}
+	if max := s.quantile(1); max < 100*time.Millisecond || max > 106*time.Millisecond {
+		t.Errorf("max = %s, want ~100ms", max)
+	}
+	if s := h.snapshot(now.Add(11 * time.Second)); s.total != 0 {
+		t.Errorf("total after the window = %d, want 0", s.total)
+	}
+}
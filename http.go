@@ -0,0 +1,202 @@
+package peek
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPOption configures HTTPMiddleware.
+type HTTPOption func(m *middleware)
+
+type middleware struct {
+	route func(r *http.Request) string
+}
+
+// RouteName sets how requests are grouped into routes in the http panel.
+// The default is the method and path, replace it if your paths contain ids.
+func RouteName(fn func(r *http.Request) string) HTTPOption {
+	return func(m *middleware) { m.route = fn }
+}
+
+func defaultRoute(r *http.Request) string {
+	return r.Method + " " + r.URL.Path
+}
+
+// maxRoutes caps how many routes are tracked, the rest is counted as "other".
+const maxRoutes = 50
+
+// httpWindow is how far back latency percentiles look.
+const httpWindow = 10 * time.Second
+
+type routeStats struct {
+	count    int64
+	statuses [6]int64 // by status class, 1xx to 5xx
+	hdr      *hdr
+
+	// rate of requests per second, refreshed once a second by the panel
+	rate     float64
+	rateFrom int64
+}
+
+type httpStats struct {
+	inFlight int64
+
+	mu     sync.Mutex
+	routes map[string]*routeStats
+	at     time.Time
+}
+
+var httpPanel = &httpStats{routes: map[string]*routeStats{}}
+
+// HTTPMiddleware tracks requests handled by next and shows them in the http panel:
+// in flight requests, request rate, status codes and latency percentiles per route, see RouteName.
+func HTTPMiddleware(next http.Handler, opts ...HTTPOption) http.Handler {
+	m := &middleware{route: defaultRoute}
+	for _, o := range opts {
+		o(m)
+	}
+	panels.Set("http", &panel{lines: httpPanel.lines})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		atomic.AddInt64(&httpPanel.inFlight, 1)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			// a panicking handler sends no response, it's counted as a 500 and the panic goes on to net/http
+			p := recover()
+			if p != nil {
+				rec.status = http.StatusInternalServerError
+			}
+			atomic.AddInt64(&httpPanel.inFlight, -1)
+			rs := httpPanel.route(m.route(r))
+			rs.hdr.observe(time.Since(start), time.Now())
+			atomic.AddInt64(&rs.count, 1)
+			if class := rec.status / 100; class > 0 && class < len(rs.statuses) {
+				atomic.AddInt64(&rs.statuses[class], 1)
+			}
+			if p != nil {
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+func (h *httpStats) route(name string) *routeStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rs, ok := h.routes[name]
+	if !ok {
+		if len(h.routes) >= maxRoutes {
+			name = "other"
+			if rs, ok = h.routes[name]; ok {
+				return rs
+			}
+		}
+		rs = &routeStats{hdr: newHDR(httpWindow)}
+		h.routes[name] = rs
+	}
+	return rs
+}
+
+func (h *httpStats) lines(wa *Watcher, width int) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	dt := now.Sub(h.at)
+	refresh := dt >= time.Second
+	if refresh {
+		h.at = now
+	}
+
+	names := make([]string, 0, len(h.routes))
+	var total float64
+	var statuses [6]int64
+	for name, rs := range h.routes {
+		names = append(names, name)
+		count := atomic.LoadInt64(&rs.count)
+		if refresh {
+			rs.rate = float64(count-rs.rateFrom) / dt.Seconds()
+			rs.rateFrom = count
+		}
+		total += rs.rate
+		for i := range statuses {
+			statuses[i] += atomic.LoadInt64(&rs.statuses[i])
+		}
+	}
+	sort.Strings(names)
+
+	out := []string{
+		wa.row("in flight", fmt.Sprint(atomic.LoadInt64(&h.inFlight)), ""),
+		wa.row("rate", si(total)+"/s", ""),
+		wa.row("status", fmt.Sprintf("2xx %d 3xx %d 4xx %d 5xx %d", statuses[2], statuses[3], statuses[4], statuses[5]), ""),
+	}
+	for _, name := range names {
+		rs := h.routes[name]
+		out = append(out, wa.row(name, si(rs.rate)+"/s",
+			fmt.Sprintf("%s, 4xx %d 5xx %d", rs.hdr.snapshot(now).summary(), atomic.LoadInt64(&rs.statuses[4]), atomic.LoadInt64(&rs.statuses[5]))))
+	}
+	return out
+}
+
+// statusRecorder remembers the status code written by the handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wrote {
+		r.status = code
+		r.wrote = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wrote = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses working.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack keeps WebSocket upgrades working, the request is counted as 101.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("peek: %T doesn't support hijacking", r.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && !r.wrote {
+		r.status = http.StatusSwitchingProtocols
+		r.wrote = true
+	}
+	return conn, rw, err
+}
+
+// ReadFrom keeps sendfile working for http.ServeContent and friends.
+func (r *statusRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.wrote = true
+	if rf, ok := r.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+	return io.Copy(r.ResponseWriter, src)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
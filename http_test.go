@@ -0,0 +1,73 @@
+package peek
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	rs := httpPanel.route("hijack")
+	before := atomic.LoadInt64(&rs.statuses[1])
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	}), RouteName(func(r *http.Request) string { return "hijack" }))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	// the middleware records the request after the handler returns, which can be after the client is done
+	for deadline := time.Now().Add(time.Second); atomic.LoadInt64(&rs.statuses[1]) == before && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt64(&rs.statuses[1]) - before; n != 1 {
+		t.Fatalf("1xx = %d, want 1", n)
+	}
+}
+
+func TestHTTPMiddlewareHijackUnsupported(t *testing.T) {
+	rec := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := rec.Hijack(); err == nil || !strings.Contains(err.Error(), "hijacking") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestHTTPMiddlewarePanic(t *testing.T) {
+	rs := httpPanel.route("panic")
+	before2xx, before5xx := atomic.LoadInt64(&rs.statuses[2]), atomic.LoadInt64(&rs.statuses[5])
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), RouteName(func(r *http.Request) string { return "panic" }))
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("recovered %v, want the handler's panic", r)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	if n := atomic.LoadInt64(&rs.statuses[5]) - before5xx; n != 1 {
+		t.Fatalf("5xx = %d, want 1", n)
+	}
+	if n := atomic.LoadInt64(&rs.statuses[2]) - before2xx; n != 0 {
+		t.Fatalf("2xx = %d, want 0", n)
+	}
+}
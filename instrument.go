@@ -0,0 +1,104 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Counter is a concurrency safe counter shown with its rate.
+type Counter struct {
+	n int64
+}
+
+// NewCounter creates a counter and adds it to the watcher.
+func NewCounter(desc string, opts ...EntryOption) *Counter {
+	c := &Counter{}
+	e := &entry{get: func() any { return c.Value() }, showRate: true}
+	funcs.Set(desc, e.with(opts))
+	return c
+}
+
+// Inc adds 1 to the counter.
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.n, 1)
+}
+
+// Add adds n to the counter.
+func (c *Counter) Add(n int64) {
+	atomic.AddInt64(&c.n, n)
+}
+
+// Value returns the current count.
+func (c *Counter) Value() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+// GaugeValue is a concurrency safe value that can go up and down, shown as its last value.
+type GaugeValue struct {
+	bits uint64
+}
+
+// NewGauge creates a gauge and adds it to the watcher.
+func NewGauge(desc string, opts ...EntryOption) *GaugeValue {
+	g := &GaugeValue{}
+	funcs.Set(desc, (&entry{get: func() any { return g.Value() }}).with(opts))
+	return g
+}
+
+// Set sets the gauge to f.
+func (g *GaugeValue) Set(f float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(f))
+}
+
+// Add adds f to the gauge, use a negative f to subtract.
+func (g *GaugeValue) Add(f float64) {
+	for {
+		old := atomic.LoadUint64(&g.bits)
+		n := math.Float64bits(math.Float64frombits(old) + f)
+		if atomic.CompareAndSwapUint64(&g.bits, old, n) {
+			return
+		}
+	}
+}
+
+// Value returns the current value.
+func (g *GaugeValue) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+// timerWindow is how far back a Timer looks for its percentiles.
+const timerWindow = time.Minute
+
+// Timer records durations and shows their count and latency percentiles over the last minute.
+type Timer struct {
+	hdr   *hdr
+	count int64
+}
+
+// NewTimer creates a timer and adds it to the watcher.
+// Use NewHistogram to see the whole distribution.
+func NewTimer(desc string, opts ...EntryOption) *Timer {
+	t := &Timer{hdr: newHDR(timerWindow)}
+	e := &entry{get: func() any { return atomic.LoadInt64(&t.count) }}
+	e.draw = func(e *entry, v any, width int) string {
+		return fmt.Sprintf("%d total, last %s: %s", v, timerWindow, t.hdr.snapshot(time.Now()).summary())
+	}
+	funcs.Set(desc, e.with(opts))
+	return t
+}
+
+// Observe records a duration.
+func (t *Timer) Observe(d time.Duration) {
+	t.hdr.observe(d, time.Now())
+	atomic.AddInt64(&t.count, 1)
+}
+
+// Start starts timing and returns a func that records the elapsed time, e.g.
+//
+//	defer t.Start()()
+func (t *Timer) Start() func() {
+	start := time.Now()
+	return func() { t.Observe(time.Since(start)) }
+}
@@ -0,0 +1,100 @@
+package peek
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// keys looked up for the time, level and message of JSON lines, first match wins
+var (
+	jsonTimeKeys  = []string{"time", "ts", "timestamp", "@timestamp"}
+	jsonLevelKeys = []string{"level", "lvl", "severity"}
+	jsonMsgKeys   = []string{"msg", "message"}
+)
+
+func init() {
+	command("fields", func(wa *Watcher, arg string) error {
+		wa.SetJSONLog(true, strings.Fields(strings.ReplaceAll(arg, ",", " "))...)
+		return nil
+	})
+}
+
+// SetJSONLog sets if JSON lines are shown as 'time level msg key=val' in the log pane, which is the default.
+// fields picks the keys shown after the message, by default all of them are.
+// The log file always gets the raw JSON.
+// In interactive mode use 'fields <key,key...>' in the command palette.
+func (wa *Watcher) SetJSONLog(enabled bool, fields ...string) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.rawJSON = !enabled
+	wa.jsonFields = fields
+}
+
+// prettyJSON renders a JSON line, other lines are returned as is. wa.mu must be held.
+func (wa *Watcher) prettyJSON(s string) string {
+	if wa.rawJSON || !strings.HasPrefix(strings.TrimSpace(s), "{") || !gjson.Valid(s) {
+		return s
+	}
+	obj := gjson.Parse(s)
+	// top level keys are looked up directly, "@timestamp" isn't a valid gjson path
+	top := obj.Map()
+	used := map[string]bool{}
+	pick := func(keys []string) string {
+		for _, k := range keys {
+			if v, ok := top[k]; ok {
+				used[k] = true
+				return v.String()
+			}
+		}
+		return ""
+	}
+	ts, level, msg := pick(jsonTimeKeys), pick(jsonLevelKeys), pick(jsonMsgKeys)
+
+	var b strings.Builder
+	if ts != "" {
+		fmt.Fprintf(&b, "%s%s%s%s ", wa.faintColour, ts, Reset, wa.logColour)
+	}
+	if level != "" {
+		fmt.Fprintf(&b, "%s%-5s%s%s ", wa.levelColour(level), strings.ToUpper(level), Reset, wa.logColour)
+	}
+	b.WriteString(msg)
+
+	field := func(k string, v gjson.Result) {
+		val := v.String()
+		if v.Type == gjson.JSON || strings.ContainsAny(val, " \"=") {
+			val = v.Raw
+		}
+		fmt.Fprintf(&b, " %s%s=%s%s", wa.faintColour, k, Reset+wa.logColour, val)
+	}
+	if len(wa.jsonFields) > 0 {
+		for _, k := range wa.jsonFields {
+			if v := obj.Get(k); v.Exists() {
+				field(k, v)
+			}
+		}
+		return b.String()
+	}
+	obj.ForEach(func(k, v gjson.Result) bool {
+		if !used[k.String()] {
+			field(k.String(), v)
+		}
+		return true
+	})
+	return b.String()
+}
+
+func (wa *Watcher) levelColour(level string) string {
+	switch strings.ToLower(level) {
+	case "trace", "debug":
+		return wa.levelColours["debug"]
+	case "info":
+		return wa.levelColours["info"]
+	case "warn", "warning":
+		return wa.levelColours["warn"]
+	case "error", "fatal", "panic", "critical":
+		return wa.levelColours["error"]
+	}
+	return wa.logColour
+}
@@ -0,0 +1,140 @@
+package peek
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/main-kube/util/safe"
+	"golang.org/x/sys/unix"
+)
+
+// keys maps hotkeys to handlers run in interactive mode.
+var keys = safe.Map[byte, func(wa *Watcher)]{}
+
+// Interactive puts the terminal in raw mode and starts reading hotkeys from stdin.
+// Press 'e' to select a Var and change its value, ':' to open the command palette, see Action,
+// 'g' to browse goroutines grouped by stack or '/' to filter the log pane.
+// Don't use it if your program reads stdin itself.
+// The terminal is restored on SIGINT and SIGTERM, call Close to restore it when the program exits normally.
+func (wa *Watcher) Interactive() {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	if wa.interactive {
+		return
+	}
+	wa.interactive = true
+
+	fd := int(os.Stdin.Fd())
+	old, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err == nil {
+		raw := *old
+		raw.Lflag &^= unix.ICANON | unix.ECHO
+		raw.Cc[unix.VMIN] = 1
+		raw.Cc[unix.VTIME] = 0
+		unix.IoctlSetTermios(fd, ioctlSetTermios, &raw)
+
+		var once sync.Once
+		restore := func() {
+			once.Do(func() { unix.IoctlSetTermios(fd, ioctlSetTermios, old) })
+		}
+		done := make(chan struct{})
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			select {
+			case s := <-sig:
+				restore()
+				signal.Reset(s)
+				syscall.Kill(os.Getpid(), s.(syscall.Signal))
+			case <-done:
+				signal.Stop(sig)
+			}
+		}()
+		wa.restore = func() {
+			restore()
+			close(done)
+		}
+	}
+	go wa.readKeys()
+}
+
+// Close stops reading hotkeys and restores the terminal if Interactive put it in raw mode.
+// Call it before the program exits, otherwise the shell is left without echo:
+//
+//	wa := peek.Create(peek.InteractiveMode())
+//	defer wa.Close()
+func (wa *Watcher) Close() {
+	wa.mu.Lock()
+	restore := wa.restore
+	wa.restore = nil
+	wa.closed = true
+	wa.mu.Unlock()
+	if restore != nil {
+		restore()
+	}
+}
+
+func (wa *Watcher) readKeys() {
+	b := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(b); err != nil {
+			return
+		}
+		wa.mu.Lock()
+		closed := wa.closed
+		wa.mu.Unlock()
+		if closed {
+			return
+		}
+		wa.handleKey(b[0])
+		wa.notify()
+	}
+}
+
+func (wa *Watcher) handleKey(k byte) {
+	if wa.viewKey(k) || wa.editKey(k) || wa.paletteKey(k) || breakKey(k) {
+		return
+	}
+	if fn := keys.Get(k); fn != nil {
+		fn(wa)
+	}
+}
+
+// view is a full screen page shown instead of the dashboard until it's closed.
+type view interface {
+	lines(wa *Watcher, width, height int) []string
+	// key handles a key press, returning true closes the view
+	key(wa *Watcher, k byte) bool
+}
+
+// open shows v instead of the dashboard.
+func (wa *Watcher) open(v view) {
+	wa.mu.Lock()
+	wa.view = v
+	wa.mu.Unlock()
+	wa.notify()
+}
+
+func (wa *Watcher) currentView() view {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	return wa.view
+}
+
+// viewKey passes the key to the open view, returns false if there is none.
+func (wa *Watcher) viewKey(k byte) bool {
+	v := wa.currentView()
+	if v == nil {
+		return false
+	}
+	if v.key(wa, k) {
+		wa.mu.Lock()
+		if wa.view == v {
+			wa.view = nil
+		}
+		wa.mu.Unlock()
+	}
+	return true
+}
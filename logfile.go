@@ -0,0 +1,203 @@
+package peek
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLogPath is where captured output is written unless SetLog says otherwise.
+// It's shared by every process using peek, their lines interleave and each one rotates the file
+// of the others, use a path with {pid} when more than one runs at a time.
+const DefaultLogPath = "/tmp/peek-var/log.txt"
+
+// LogConfig configures the file everything printed by the program is copied to.
+type LogConfig struct {
+	// Path of the log file, {pid} is replaced with the process id and {time} with the start time,
+	// e.g. "/tmp/peek-var/log-{pid}.txt". Empty disables the log file.
+	Path string
+	// MaxSize rotates the file once it grows bigger than this many bytes, 0 never rotates on size.
+	MaxSize int64
+	// MaxAge rotates the file once this process has been writing to it for longer than this,
+	// 0 never rotates on age.
+	MaxAge time.Duration
+	// MaxBackups is how many rotated files are kept, 0 keeps all of them.
+	MaxBackups int
+	// MaxBackupAge removes rotated files older than this, 0 keeps them forever.
+	MaxBackupAge time.Duration
+	// Compress gzips rotated files.
+	Compress bool
+}
+
+// logWriter appends to the log file, opening it on the first write and rotating it as configured.
+type logWriter struct {
+	mu     sync.Mutex
+	cfg    LogConfig
+	path   string
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+var (
+	logs      = &logWriter{cfg: LogConfig{Path: DefaultLogPath}}
+	startTime = time.Now()
+)
+
+// SetLog changes where and how the log file is written, the current file is closed.
+func (wa *Watcher) SetLog(cfg LogConfig) {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	logs.close()
+	logs.cfg = cfg
+}
+
+// expandPath fills in the {pid} and {time} placeholders.
+func expandPath(path string) string {
+	return strings.NewReplacer(
+		"{pid}", strconv.Itoa(os.Getpid()),
+		"{time}", startTime.Format("20060102-150405"),
+	).Replace(path)
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cfg.Path == "" {
+		return len(p), nil
+	}
+	if l.f != nil && l.needsRotation(int64(len(p))) {
+		l.rotate()
+	}
+	if l.f == nil {
+		if err := l.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+func (l *logWriter) open() error {
+	l.path = expandPath(l.cfg.Path)
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	// appending so a restart doesn't wipe the previous run
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	l.size = 0
+	// the file's age counts from here, its modification time says nothing about when it was created
+	l.opened = time.Now()
+	if fi, err := f.Stat(); err == nil {
+		l.size = fi.Size()
+	}
+	return nil
+}
+
+func (l *logWriter) close() {
+	if l.f != nil {
+		l.f.Close()
+		l.f = nil
+	}
+}
+
+func (l *logWriter) needsRotation(n int64) bool {
+	return (l.cfg.MaxSize > 0 && l.size+n > l.cfg.MaxSize) ||
+		(l.cfg.MaxAge > 0 && time.Since(l.opened) > l.cfg.MaxAge)
+}
+
+// backupFormat is the timestamp suffix of rotated files, e.g. log.txt.20261019-150405.000.
+const backupFormat = "20060102-150405.000"
+
+// rotate renames the current file with a timestamp suffix, the next write opens a new one.
+func (l *logWriter) rotate() {
+	l.close()
+	backup := fmt.Sprintf("%s.%s", l.path, time.Now().Format(backupFormat))
+	if err := os.Rename(l.path, backup); err != nil {
+		return
+	}
+	cfg, path := l.cfg, l.path
+	go func() {
+		if cfg.Compress {
+			compress(backup)
+		}
+		prune(path, cfg)
+	}()
+}
+
+func compress(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// prune removes the oldest backups past MaxBackups and the ones older than MaxBackupAge.
+// Only files named like rotate names them are touched, anything else next to the log is left alone.
+func prune(path string, cfg LogConfig) {
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	prefix := filepath.Base(path) + "."
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || e.IsDir() {
+			continue
+		}
+		suffix := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz")
+		if _, err := time.Parse(backupFormat, suffix); err == nil && len(suffix) == len(backupFormat) {
+			backups = append(backups, filepath.Join(filepath.Dir(path), name))
+		}
+	}
+	// the timestamp suffix sorts oldest first
+	sort.Strings(backups)
+	for i, b := range backups {
+		old := false
+		if fi, err := os.Stat(b); err == nil && cfg.MaxBackupAge > 0 {
+			old = time.Since(fi.ModTime()) > cfg.MaxBackupAge
+		}
+		if old || (cfg.MaxBackups > 0 && i < len(backups)-cfg.MaxBackups) {
+			os.Remove(b)
+		}
+	}
+}
+
+// logDir is the directory of the log file, dumps and profiles are saved there too.
+func logDir() string {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	if logs.cfg.Path == "" {
+		return filepath.Dir(DefaultLogPath)
+	}
+	return filepath.Dir(expandPath(logs.cfg.Path))
+}
@@ -0,0 +1,81 @@
+package peek
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogRotatesOnSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	l := &logWriter{cfg: LogConfig{Path: path, MaxSize: 10}}
+	defer l.close()
+	l.Write([]byte("0123456789"))
+	l.Write([]byte("abc"))
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want 1", backups)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "abc" {
+		t.Fatalf("log = %q, want %q", b, "abc")
+	}
+}
+
+func TestLogAgeCountsFromOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	// a file written recently by a previous run
+	os.WriteFile(path, []byte("previous run\n"), 0644)
+	l := &logWriter{cfg: LogConfig{Path: path, MaxAge: time.Hour}}
+	defer l.close()
+	l.Write([]byte("x\n"))
+	if l.needsRotation(0) {
+		t.Fatal("rotating a file that was just opened")
+	}
+	l.opened = time.Now().Add(-2 * time.Hour)
+	if !l.needsRotation(0) {
+		t.Fatal("not rotating a file opened 2h ago")
+	}
+}
+
+func TestPruneKeepsForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app")
+	old := time.Now().Add(-48 * time.Hour)
+	files := map[string]bool{
+		"app.json":                    false,
+		"app.20261017-101010":         false,
+		"app.20261017-101010.000.bak": false,
+		"app.20261017-101010.000":     true,
+		"app.20261018-101010.000.gz":  true,
+	}
+	for name := range files {
+		p := filepath.Join(dir, name)
+		os.WriteFile(p, nil, 0644)
+		os.Chtimes(p, old, old)
+	}
+
+	prune(path, LogConfig{Path: path, MaxBackupAge: time.Hour})
+	for name, backup := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if gone := os.IsNotExist(err); gone != backup {
+			t.Errorf("%s removed = %v, want %v", name, gone, backup)
+		}
+	}
+}
+
+func TestPruneMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app")
+	for _, name := range []string{"app.json", "app.20261017-101010.000", "app.20261018-101010.000.gz"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+
+	prune(path, LogConfig{Path: path, MaxBackups: 1})
+	for name, want := range map[string]bool{"app.json": true, "app.20261017-101010.000": false, "app.20261018-101010.000.gz": true} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+			t.Errorf("%s kept = %v, want %v", name, err == nil, want)
+		}
+	}
+}
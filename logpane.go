@@ -0,0 +1,206 @@
+package peek
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// TimeFormat is how captured lines are timestamped in the log pane and the log file.
+type TimeFormat int
+
+const (
+	// TimeNone doesn't show timestamps.
+	TimeNone TimeFormat = iota
+	// TimeAbsolute shows the wall clock time the line arrived at.
+	TimeAbsolute
+	// TimeRelative shows the time since the program started.
+	TimeRelative
+	// TimeDelta shows the time since the previous line.
+	TimeDelta
+)
+
+// Sources of captured lines, lines from stdout aren't tagged.
+const (
+	SourceStdout = "stdout"
+	SourceStderr = "stderr"
+	SourceSlog   = "slog"
+	SourcePeek   = "peek"
+)
+
+// maxLogLines is how many captured lines are kept for the log pane.
+const maxLogLines = 1000
+
+// logLine is a single captured line.
+type logLine struct {
+	at   time.Time
+	src  string
+	text string
+}
+
+// capture splits what's written to it into lines tagged with src.
+type capture struct {
+	wa      *Watcher
+	src     string
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (c *capture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	c.pending = append(c.pending, p...)
+	for {
+		i := bytes.IndexByte(c.pending, '\n')
+		if i < 0 {
+			break
+		}
+		c.wa.addLine(c.src, string(c.pending[:i]))
+		c.pending = c.pending[i+1:]
+	}
+	c.mu.Unlock()
+	c.wa.notify()
+	return len(p), nil
+}
+
+// partial returns the unfinished last line, it's shown but not logged until it ends.
+func (c *capture) partial() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return string(c.pending)
+}
+
+// Writer returns a writer whose lines end up in the log pane tagged with source,
+// e.g. slog.New(slog.NewTextHandler(wa.Writer(peek.SourceSlog), nil)).
+func (wa *Watcher) Writer(source string) io.Writer {
+	c := &capture{wa: wa, src: source}
+	wa.mu.Lock()
+	wa.captures = append(wa.captures, c)
+	wa.mu.Unlock()
+	return c
+}
+
+// CaptureStderr redirects os.Stderr to the log pane, tagging its lines with stderr.
+func (wa *Watcher) CaptureStderr() {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return
+	}
+	os.Stderr = w
+	go wa.read(r, wa.Writer(SourceStderr))
+}
+
+// SetTimestamps sets how lines are timestamped in the log pane and the log file.
+func (wa *Watcher) SetTimestamps(f TimeFormat) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.timeFormat = f
+}
+
+func (wa *Watcher) read(r *os.File, w io.Writer) {
+	b := make([]byte, 1024)
+	for {
+		i, err := r.Read(b)
+		if err != nil {
+			return
+		}
+		w.Write(b[:i])
+	}
+}
+
+// addLine stores a complete line and copies it to the log file.
+func (wa *Watcher) addLine(src, text string) {
+	l := logLine{at: time.Now(), src: src, text: text}
+	wa.mu.Lock()
+	prev := wa.lastAt
+	wa.lastAt = l.at
+	wa.lines = append(wa.lines, l)
+	if len(wa.lines) > maxLogLines {
+		wa.lines = append(wa.lines[:0], wa.lines[len(wa.lines)-maxLogLines:]...)
+	}
+	s := wa.formatLine(l, prev) + "\n"
+	wa.mu.Unlock()
+	logs.Write([]byte(s))
+	if src != SourcePeek {
+		scanLine(text)
+	}
+}
+
+// formatLine prefixes the line with its timestamp and source tag, wa.mu must be held.
+func (wa *Watcher) formatLine(l logLine, prev time.Time) string {
+	prefix := ""
+	switch wa.timeFormat {
+	case TimeAbsolute:
+		prefix = l.at.Format("15:04:05.000 ")
+	case TimeRelative:
+		prefix = fmt.Sprintf("+%-10s ", l.at.Sub(startTime).Round(time.Millisecond))
+	case TimeDelta:
+		if prev.IsZero() {
+			prev = l.at
+		}
+		prefix = fmt.Sprintf("Δ%-10s ", l.at.Sub(prev).Round(time.Microsecond))
+	}
+	if l.src != SourceStdout {
+		prefix += "[" + l.src + "] "
+	}
+	return prefix + l.text
+}
+
+// SetLogWrap sets if long lines in the log pane wrap, the default, or get truncated to the terminal width.
+func (wa *Watcher) SetLogWrap(wrap bool) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.noWrap = !wrap
+}
+
+// logPane returns the last n rows of the log, including unfinished lines,
+// wrapped or truncated to the terminal width.
+func (wa *Watcher) logPane(n int) []string {
+	// captures lock themselves before wa.mu, so they're read first
+	wa.mu.Lock()
+	captures := wa.captures
+	wa.mu.Unlock()
+	var partial []string
+	for _, c := range captures {
+		if p := c.partial(); p != "" {
+			partial = append(partial, p)
+		}
+	}
+
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	var rows []string
+	// newest lines first, until the pane is full
+	add := func(s string) {
+		if !wa.shown(s) {
+			return
+		}
+		s = wa.logColour + wa.highlight(s)
+		if wa.noWrap {
+			rows = append(rows, truncate(s, wa.cols)+Reset)
+			return
+		}
+		wrapped := wrap(s, wa.cols)
+		for i := len(wrapped) - 1; i >= 0 && len(rows) < n; i-- {
+			rows = append(rows, wrapped[i])
+		}
+	}
+	for i := len(partial) - 1; i >= 0 && len(rows) < n; i-- {
+		add(partial[i])
+	}
+	for i := len(wa.lines) - 1; i >= 0 && len(rows) < n; i-- {
+		prev := time.Time{}
+		if i > 0 {
+			prev = wa.lines[i-1].at
+		}
+		l := wa.lines[i]
+		l.text = wa.prettyJSON(l.text)
+		add(wa.formatLine(l, prev))
+	}
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+	return rows
+}
@@ -0,0 +1,45 @@
+package peek
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatLine(t *testing.T) {
+	at := time.Date(2026, 10, 19, 14, 3, 7, 250_000_000, time.Local)
+	prev := at.Add(-1500 * time.Microsecond)
+	for _, tc := range []struct {
+		format TimeFormat
+		l      logLine
+		prev   time.Time
+		want   string
+	}{
+		{TimeNone, logLine{at: at, src: SourceStdout, text: "hi"}, prev, "hi"},
+		{TimeNone, logLine{at: at, src: SourceStderr, text: "oops"}, prev, "[stderr] oops"},
+		{TimeAbsolute, logLine{at: at, src: SourceStdout, text: "hi"}, prev, "14:03:07.250 hi"},
+		{TimeAbsolute, logLine{at: at, src: SourceSlog, text: "hi"}, prev, "14:03:07.250 [slog] hi"},
+		{TimeRelative, logLine{at: startTime.Add(1500 * time.Millisecond), src: SourceStdout, text: "hi"}, prev, "+1.5s       hi"},
+		{TimeDelta, logLine{at: at, src: SourceStdout, text: "hi"}, prev, "Δ1.5ms      hi"},
+		{TimeDelta, logLine{at: at, src: SourcePeek, text: "first"}, time.Time{}, "Δ0s         [peek] first"},
+	} {
+		wa := &Watcher{timeFormat: tc.format}
+		if got := wa.formatLine(tc.l, tc.prev); got != tc.want {
+			t.Errorf("format %d: formatLine = %q, want %q", tc.format, got, tc.want)
+		}
+	}
+}
+
+func TestCaptureSplitsLines(t *testing.T) {
+	wa := &Watcher{}
+	w := wa.Writer(SourceStderr)
+	w.Write([]byte("one\ntw"))
+	w.Write([]byte("o\nthree"))
+	var got []string
+	for _, l := range wa.lines {
+		got = append(got, l.src+":"+l.text)
+	}
+	if strings.Join(got, ",") != "stderr:one,stderr:two" {
+		t.Errorf("lines = %v", got)
+	}
+}
@@ -0,0 +1,12 @@
+package peek
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// tests shouldn't write to the shared default log file
+	logs.cfg.Path = ""
+	os.Exit(m.Run())
+}
@@ -0,0 +1,225 @@
+package peek
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultInterval is how often the dashboard is redrawn unless Interval says otherwise.
+const DefaultInterval = 100 * time.Millisecond
+
+// OutputMode is what peek does with the terminal.
+type OutputMode int
+
+const (
+	// OutputDashboard takes over the terminal and draws the dashboard, the default.
+	OutputDashboard OutputMode = iota
+	// OutputOff leaves the terminal and stdout alone, registered entries are kept but nothing is drawn.
+	// Breakpoints are ignored as there is no one to resume them.
+	OutputOff
+)
+
+// config is everything Create needs before the render goroutine starts.
+type config struct {
+	interval    time.Duration
+	theme       Theme
+	colourMode  ColourMode
+	hight       uint16
+	width       uint16
+	whHardSet   bool
+	log         LogConfig
+	capture     []string
+	output      OutputMode
+	timestamps  TimeFormat
+	interactive bool
+}
+
+// Option configures Create.
+type Option func(c *config)
+
+// Interval sets how often the dashboard is redrawn, it's also redrawn when something is logged.
+// PEEK_INTERVAL overrides it, e.g. PEEK_INTERVAL=500ms. Durations that aren't positive are ignored.
+func Interval(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// WithTheme sets the colours, see Theme. PEEK_THEME overrides it with dark, light or a path to a JSON theme.
+func WithTheme(t Theme) Option {
+	return func(c *config) { c.theme = t }
+}
+
+// WithColourMode overrides the detected colour support. PEEK_COLOUR overrides it with none, 16, 256 or true.
+func WithColourMode(m ColourMode) Option {
+	return func(c *config) { c.colourMode = m }
+}
+
+// Dimensions sets the size of the terminal instead of asking it, see SetDimentions.
+// PEEK_DIMENSIONS overrides it, e.g. PEEK_DIMENSIONS=40x120 for 40 rows and 120 columns.
+func Dimensions(h, w int) Option {
+	return func(c *config) {
+		c.hight, c.width = uint16(h), uint16(w)
+		c.whHardSet = true
+	}
+}
+
+// LogFile configures the log file, see LogConfig.
+// PEEK_LOG overrides the path, "off" disables the file, PEEK_LOG_MAX_SIZE overrides the size it's rotated at.
+func LogFile(cfg LogConfig) Option {
+	return func(c *config) { c.log = cfg }
+}
+
+// LogPath sets the log file path keeping the rest of the log config, see LogConfig.
+func LogPath(path string) Option {
+	return func(c *config) { c.log.Path = path }
+}
+
+// Capture sets which streams are shown in the log pane, SourceStdout and/or SourceStderr.
+// Only stdout is captured by default. PEEK_CAPTURE overrides it, e.g. PEEK_CAPTURE=stdout,stderr.
+func Capture(streams ...string) Option {
+	return func(c *config) { c.capture = streams }
+}
+
+// Output sets what peek does with the terminal. PEEK_OUTPUT overrides it with dashboard or off.
+func Output(m OutputMode) Option {
+	return func(c *config) { c.output = m }
+}
+
+// Timestamps sets how captured lines are timestamped.
+// PEEK_TIMESTAMPS overrides it with none, absolute, relative or delta.
+func Timestamps(f TimeFormat) Option {
+	return func(c *config) { c.timestamps = f }
+}
+
+// InteractiveMode turns on hotkeys, see Watcher.Interactive. PEEK_INTERACTIVE=1 turns it on too.
+func InteractiveMode() Option {
+	return func(c *config) { c.interactive = true }
+}
+
+// fromEnv applies the PEEK_* variables on top of the options.
+// Bad values are reported on stderr and ignored, it's too early to show them on the dashboard.
+func (c *config) fromEnv() {
+	env := func(name string, fn func(v string) error) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if err := fn(v); err != nil {
+			fmt.Fprintf(os.Stderr, "peek: ignoring %s=%q: %v\n", name, v, err)
+		}
+	}
+	env("PEEK_INTERVAL", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err == nil && d <= 0 {
+			err = fmt.Errorf("must be positive")
+		}
+		if err == nil {
+			c.interval = d
+		}
+		return err
+	})
+	env("PEEK_THEME", func(v string) error {
+		switch v {
+		case "dark":
+			c.theme = DarkTheme
+		case "light":
+			c.theme = LightTheme
+		default:
+			t, err := LoadTheme(v)
+			if err != nil {
+				return err
+			}
+			c.theme = t
+		}
+		return nil
+	})
+	env("PEEK_COLOUR", func(v string) error {
+		modes := map[string]ColourMode{"none": NoColour, "16": Colour16, "256": Colour256, "true": TrueColour}
+		m, ok := modes[v]
+		if !ok {
+			return fmt.Errorf("want none, 16, 256 or true")
+		}
+		c.colourMode = m
+		return nil
+	})
+	env("PEEK_DIMENSIONS", func(v string) error {
+		h, w, ok := strings.Cut(v, "x")
+		rows, err1 := strconv.ParseUint(h, 10, 16)
+		cols, err2 := strconv.ParseUint(w, 10, 16)
+		if !ok || err1 != nil || err2 != nil {
+			return fmt.Errorf("want ROWSxCOLUMNS")
+		}
+		Dimensions(int(rows), int(cols))(c)
+		return nil
+	})
+	env("PEEK_LOG", func(v string) error {
+		if v == "off" {
+			v = ""
+		}
+		c.log.Path = v
+		return nil
+	})
+	env("PEEK_LOG_MAX_SIZE", func(v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err == nil {
+			c.log.MaxSize = n
+		}
+		return err
+	})
+	env("PEEK_CAPTURE", func(v string) error {
+		var capture []string
+		for _, s := range strings.Split(v, ",") {
+			switch s = strings.TrimSpace(s); s {
+			case SourceStdout, SourceStderr:
+				capture = append(capture, s)
+			case "":
+			default:
+				return fmt.Errorf("unknown stream %q", s)
+			}
+		}
+		c.capture = capture
+		return nil
+	})
+	env("PEEK_OUTPUT", func(v string) error {
+		switch v {
+		case "dashboard":
+			c.output = OutputDashboard
+		case "off":
+			c.output = OutputOff
+		default:
+			return fmt.Errorf("want dashboard or off")
+		}
+		return nil
+	})
+	env("PEEK_TIMESTAMPS", func(v string) error {
+		formats := map[string]TimeFormat{"none": TimeNone, "absolute": TimeAbsolute, "relative": TimeRelative, "delta": TimeDelta}
+		f, ok := formats[v]
+		if !ok {
+			return fmt.Errorf("want none, absolute, relative or delta")
+		}
+		c.timestamps = f
+		return nil
+	})
+	env("PEEK_INTERACTIVE", func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err == nil {
+			c.interactive = b
+		}
+		return err
+	})
+}
+
+func (c *config) captures(stream string) bool {
+	for _, s := range c.capture {
+		if s == stream {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package peek
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterval(t *testing.T) {
+	for _, tc := range []struct {
+		d, want time.Duration
+	}{
+		{time.Second, time.Second},
+		{0, DefaultInterval},
+		{-time.Second, DefaultInterval},
+	} {
+		c := &config{interval: DefaultInterval}
+		Interval(tc.d)(c)
+		if c.interval != tc.want {
+			t.Errorf("Interval(%s) = %s, want %s", tc.d, c.interval, tc.want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("PEEK_INTERVAL", "250ms")
+	t.Setenv("PEEK_DIMENSIONS", "40x120")
+	t.Setenv("PEEK_LOG", "off")
+	t.Setenv("PEEK_CAPTURE", "stderr")
+	t.Setenv("PEEK_COLOUR", "256")
+	t.Setenv("PEEK_THEME", "light")
+	t.Setenv("PEEK_TIMESTAMPS", "delta")
+	t.Setenv("PEEK_OUTPUT", "off")
+	t.Setenv("PEEK_INTERACTIVE", "1")
+
+	c := &config{interval: DefaultInterval, log: LogConfig{Path: DefaultLogPath}, capture: []string{SourceStdout}}
+	c.fromEnv()
+	if c.interval != 250*time.Millisecond {
+		t.Errorf("interval = %s", c.interval)
+	}
+	if !c.whHardSet || c.hight != 40 || c.width != 120 {
+		t.Errorf("dimensions = %dx%d, hard set %v", c.hight, c.width, c.whHardSet)
+	}
+	if c.log.Path != "" {
+		t.Errorf("log path = %q", c.log.Path)
+	}
+	if c.captures(SourceStdout) || !c.captures(SourceStderr) {
+		t.Errorf("capture = %v", c.capture)
+	}
+	if c.colourMode != Colour256 || c.theme != LightTheme || c.timestamps != TimeDelta || c.output != OutputOff || !c.interactive {
+		t.Errorf("config = %+v", c)
+	}
+}
+
+func TestFromEnvIgnoresBadValues(t *testing.T) {
+	t.Setenv("PEEK_INTERVAL", "-1s")
+	t.Setenv("PEEK_DIMENSIONS", "40")
+	t.Setenv("PEEK_CAPTURE", "stderr,nope")
+	t.Setenv("PEEK_COLOUR", "lots")
+
+	c := &config{interval: DefaultInterval, colourMode: Colour16, capture: []string{SourceStdout}}
+	c.fromEnv()
+	if c.interval != DefaultInterval || c.whHardSet || c.colourMode != Colour16 || !c.captures(SourceStdout) || c.captures(SourceStderr) {
+		t.Errorf("config = %+v", c)
+	}
+}
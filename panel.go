@@ -0,0 +1,89 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/main-kube/util/safe"
+)
+
+// panel is a titled group of lines drawn below the entries.
+type panel struct {
+	lines func(wa *Watcher, width int) []string
+}
+
+var panels = safe.SortedMap[string, *panel]{}
+
+// panel draws the panel with its header, using the full terminal width.
+func (wa *Watcher) panel(title string, p *panel) string {
+	head := fmt.Sprintf("%s── %s%s %s", wa.borderColour, wa.headerColour, title, Reset+wa.borderColour)
+	if n := wa.cols - displayWidth(head); n > 0 {
+		head += strings.Repeat("─", n)
+	}
+	out := head + Reset + "\n"
+	for _, l := range p.lines(wa, wa.cols) {
+		out += truncate(l, wa.cols) + Reset + "\n"
+	}
+	return out
+}
+
+// row formats a panel line as an aligned label, value and optional sparkline.
+func (wa *Watcher) row(label, value, spark string) string {
+	return fmt.Sprintf("%s%-16s%s%s%-14s%s %s%s", wa.descColour, label, Reset, wa.valueColour, value, Reset, wa.faintColour, spark)
+}
+
+// history keeps the last samples of a value for sparklines.
+type history struct {
+	buf  []float64
+	next int
+	full bool
+}
+
+func newHistory(n int) *history {
+	return &history{buf: make([]float64, n)}
+}
+
+func (h *history) push(f float64) {
+	h.buf[h.next] = f
+	h.next = (h.next + 1) % len(h.buf)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// values returns the samples, oldest first.
+func (h *history) values() []float64 {
+	if !h.full {
+		return append([]float64(nil), h.buf[:h.next]...)
+	}
+	return append(append([]float64(nil), h.buf[h.next:]...), h.buf[:h.next]...)
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the last width values scaled between their min and max.
+func sparkline(vals []float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(vals) > width {
+		vals = vals[len(vals)-width:]
+	}
+	if len(vals) == 0 {
+		return ""
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range vals {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	out := make([]rune, len(vals))
+	for i, v := range vals {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparks)-1))
+		}
+		out[i] = sparks[idx]
+	}
+	return string(out)
+}
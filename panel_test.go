@@ -0,0 +1,20 @@
+package peek
+
+import "testing"
+
+func TestSparklineWidth(t *testing.T) {
+	vals := []float64{1, 2, 3, 4}
+	for _, tc := range []struct {
+		width int
+		want  string
+	}{
+		{-12, ""},
+		{0, ""},
+		{2, "▁█"},
+		{10, "▁▃▅█"},
+	} {
+		if got := sparkline(vals, tc.width); got != tc.want {
+			t.Errorf("sparkline(%v, %d) = %q, want %q", vals, tc.width, got, tc.want)
+		}
+	}
+}
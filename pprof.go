@@ -0,0 +1,84 @@
+package peek
+
+import (
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+)
+
+// profileDuration is how long CPU profiles and traces run for.
+const profileDuration = 10 * time.Second
+
+func init() {
+	addAction("cpu profile", 'P', captureCPU)
+	addAction("heap profile", 'H', captureHeap)
+	addAction("trace", 'T', captureTrace)
+}
+
+// captureCPU profiles the CPU for profileDuration and logs the top functions.
+func captureCPU(wa *Watcher) error {
+	path := dumpPath(fmt.Sprintf("cpu-%s.pprof", time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := pprof.StartCPUProfile(f); err != nil {
+		return err
+	}
+	wa.log(fmt.Sprintf("cpu profile started for %s", profileDuration))
+	time.Sleep(profileDuration)
+	pprof.StopCPUProfile()
+	return logProfile(wa, "cpu profile", path)
+}
+
+// captureHeap writes a heap profile and logs the top functions by in use memory.
+func captureHeap(wa *Watcher) error {
+	path := dumpPath(fmt.Sprintf("heap-%s.pprof", time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := pprof.Lookup("heap").WriteTo(f, 0); err != nil {
+		return err
+	}
+	return logProfile(wa, "heap profile", path)
+}
+
+// captureTrace runs the execution tracer for profileDuration.
+func captureTrace(wa *Watcher) error {
+	path := dumpPath(fmt.Sprintf("trace-%s.out", time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := trace.Start(f); err != nil {
+		return err
+	}
+	wa.log(fmt.Sprintf("trace started for %s", profileDuration))
+	time.Sleep(profileDuration)
+	trace.Stop()
+	wa.log(fmt.Sprintf("trace saved to %s, open it with 'go tool trace %s'", path, path))
+	return nil
+}
+
+// logProfile logs where the profile was saved and its top 10 functions.
+func logProfile(wa *Watcher, name, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	wa.log(fmt.Sprintf("%s saved to %s", name, path))
+	top, err := topFunctions(b, 10)
+	if err != nil {
+		return err
+	}
+	for _, l := range top {
+		wa.log("  " + l)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package peek
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is how long a Func can run before the dashboard stops waiting for it.
+const DefaultTimeout = 200 * time.Millisecond
+
+// probe runs a Func with a timeout and recovers its panics,
+// so one bad callback can't freeze or kill the dashboard.
+type probe struct {
+	fn      func() any
+	timeout time.Duration
+	every   time.Duration
+	async   bool
+	loop    sync.Once
+
+	mu      sync.Mutex
+	running chan struct{}
+	last    any
+	at      time.Time
+	started time.Time
+	err     string
+	took    time.Duration
+}
+
+// Timeout sets how long the dashboard waits for a Func, DefaultTimeout by default.
+// A Func that takes longer keeps running in the background and its last good value is shown with a timeout marker.
+func Timeout(d time.Duration) EntryOption {
+	return optionFunc(func(e *entry) {
+		if e.probe != nil {
+			e.probe.timeout = d
+		}
+	})
+}
+
+// Every makes a Func sample at most once per d, the cached value is shown in between.
+// Use it for probes that are too expensive to run on every render.
+func Every(d time.Duration) EntryOption {
+	return optionFunc(func(e *entry) {
+		if e.probe != nil {
+			e.probe.every = d
+		}
+	})
+}
+
+// Async makes a Func sample in its own goroutine, once per Every period or once a second by default.
+// The dashboard never waits for it and shows the latest value and its age.
+func Async() EntryOption {
+	return optionFunc(func(e *entry) {
+		if e.probe != nil {
+			e.probe.async = true
+		}
+	})
+}
+
+// get returns the last good value, calling fn first unless the previous call is still running
+// or the cached value is still fresh. A call that timed out isn't waited for again.
+func (p *probe) get() any {
+	if p.async {
+		p.loop.Do(func() { go p.background() })
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.last
+	}
+
+	p.mu.Lock()
+	if p.every > 0 && time.Since(p.started) < p.every {
+		defer p.mu.Unlock()
+		return p.last
+	}
+	running := p.running
+	if running != nil && p.err == "timeout" {
+		// it has already been waited for once, waiting again on every render would freeze the dashboard
+		defer p.mu.Unlock()
+		return p.last
+	}
+	if running == nil {
+		running = p.start()
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-running:
+	case <-time.After(p.timeout):
+		p.mu.Lock()
+		if p.running == running {
+			p.err = "timeout"
+		}
+		p.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last
+}
+
+// cached returns the last good value without calling fn, false if there is none yet.
+func (p *probe) cached() (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last, !p.at.IsZero()
+}
+
+// background samples forever, a call that's still running is waited for rather than overlapped.
+func (p *probe) background() {
+	every := p.every
+	if every <= 0 {
+		every = time.Second
+	}
+	for {
+		p.mu.Lock()
+		running := p.running
+		if running == nil {
+			running = p.start()
+		}
+		p.mu.Unlock()
+		<-running
+		time.Sleep(every)
+	}
+}
+
+// start runs fn in a new goroutine, p.mu must be held.
+func (p *probe) start() chan struct{} {
+	running := make(chan struct{})
+	p.running = running
+	p.started = time.Now()
+	go p.call(running)
+	return running
+}
+
+func (p *probe) call(done chan struct{}) {
+	start := time.Now()
+	var v any
+	var err string
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Sprintf("panic: %v", r)
+		}
+		p.mu.Lock()
+		if err == "" {
+			p.last = v
+			p.at = time.Now()
+		}
+		p.err = err
+		p.took = time.Since(start)
+		p.running = nil
+		p.mu.Unlock()
+		close(done)
+	}()
+	v = p.fn()
+}
+
+// status returns the error marker and how long the last call took.
+func (p *probe) status(wa *Watcher) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := fmt.Sprintf(" %s(%s)", wa.faintColour, p.took.Round(time.Microsecond))
+	if (p.async || p.every > 0) && !p.at.IsZero() {
+		s = fmt.Sprintf(" %s(%s, %s ago)", wa.faintColour, p.took.Round(time.Microsecond), time.Since(p.at).Round(100*time.Millisecond))
+	}
+	if p.err != "" {
+		s = fmt.Sprintf(" %s[%s]%s", wa.alertColour, p.err, s)
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package peek
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbeTimeoutWaitsOnce(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	p := &probe{fn: func() any { <-release; return 1 }, timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if v := p.get(); v != nil {
+			t.Fatalf("get = %v, want nil", v)
+		}
+	}
+	if d := time.Since(start); d > 150*time.Millisecond {
+		t.Fatalf("5 renders of a hung Func took %s, want one timeout", d)
+	}
+	if p.err != "timeout" {
+		t.Fatalf("err = %q, want timeout", p.err)
+	}
+}
+
+func TestProbeRecoversAfterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	n := 0
+	p := &probe{fn: func() any {
+		if n++; n == 1 {
+			<-release
+		}
+		return n
+	}, timeout: 20 * time.Millisecond}
+
+	p.get()
+	close(release)
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if v := p.get(); v == 2 {
+			return
+		}
+	}
+	t.Fatal("probe didn't recover after the hung call returned")
+}
+
+func TestProbePanic(t *testing.T) {
+	p := &probe{fn: func() any { panic("boom") }, timeout: time.Second}
+	if v := p.get(); v != nil {
+		t.Fatalf("get = %v, want nil", v)
+	}
+	if p.err != "panic: boom" {
+		t.Fatalf("err = %q", p.err)
+	}
+}
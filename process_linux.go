@@ -0,0 +1,127 @@
+package peek
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// clock ticks per second used in /proc/self/stat, it's 100 on every linux that matters
+const clkTck = 100
+
+// Process adds a panel with CPU usage, RSS, open file descriptors, threads and context switches
+// of the current process, read from /proc/self.
+func Process() {
+	p := &processPanel{
+		cpu: newHistory(sparkWidth),
+		rss: newHistory(sparkWidth),
+		fds: newHistory(sparkWidth),
+	}
+	panels.Set("process", &panel{lines: p.lines})
+}
+
+type processPanel struct {
+	mu    sync.Mutex
+	at    time.Time
+	ticks float64
+	pct   float64
+
+	cpu *history
+	rss *history
+	fds *history
+}
+
+func (p *processPanel) lines(wa *Watcher, width int) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ticks, err := cpuTicks()
+	if err != nil {
+		return []string{wa.row("error", err.Error(), "")}
+	}
+	status := procStatus()
+	rss := status["VmRSS"] * 1024
+	fds := openFDs()
+
+	now := time.Now()
+	if dt := now.Sub(p.at); dt >= time.Second {
+		if !p.at.IsZero() {
+			p.pct = (ticks - p.ticks) / clkTck / dt.Seconds() * 100
+			p.cpu.push(p.pct)
+		}
+		p.rss.push(rss)
+		p.fds.push(float64(fds))
+		p.ticks, p.at = ticks, now
+	}
+
+	limit := "?"
+	var rl unix.Rlimit
+	if unix.Getrlimit(unix.RLIMIT_NOFILE, &rl) == nil {
+		limit = strconv.FormatUint(rl.Cur, 10)
+	}
+
+	spark := width - 32
+	return []string{
+		wa.row("cpu", fmt.Sprintf("%.1f%%", p.pct), sparkline(p.cpu.values(), spark)),
+		wa.row("rss", humanBytes(rss), sparkline(p.rss.values(), spark)),
+		wa.row("fds", fmt.Sprintf("%d/%s", fds, limit), sparkline(p.fds.values(), spark)),
+		wa.row("threads", fmt.Sprintf("%.0f", status["Threads"]), ""),
+		wa.row("ctx switches", fmt.Sprintf("%.0f vol %.0f invol", status["voluntary_ctxt_switches"], status["nonvoluntary_ctxt_switches"]), ""),
+	}
+}
+
+// cpuTicks returns utime+stime from /proc/self/stat.
+func cpuTicks() (float64, error) {
+	b, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, err
+	}
+	// comm can contain spaces and parens, the fields we want are after the last ')'
+	s := string(b)
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	// utime and stime are fields 14 and 15, fields[0] is field 3
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("unexpected /proc/self/stat: %q", s)
+	}
+	utime, _ := strconv.ParseFloat(fields[11], 64)
+	stime, _ := strconv.ParseFloat(fields[12], 64)
+	return utime + stime, nil
+}
+
+// procStatus returns the numeric fields of /proc/self/status, sizes in kB.
+func procStatus() map[string]float64 {
+	m := map[string]float64{}
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return m
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		if fields := strings.Fields(v); len(fields) > 0 {
+			if n, err := strconv.ParseFloat(fields[0], 64); err == nil {
+				m[k] = n
+			}
+		}
+	}
+	return m
+}
+
+func openFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0
+	}
+	// ReadDir opens one fd itself
+	return len(entries) - 1
+}
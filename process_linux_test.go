@@ -0,0 +1,21 @@
+package peek
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessPanelNarrow(t *testing.T) {
+	p := &processPanel{
+		cpu: newHistory(sparkWidth),
+		rss: newHistory(sparkWidth),
+		fds: newHistory(sparkWidth),
+	}
+	wa := &Watcher{}
+	for _, width := range []int{80, 20, 0} {
+		p.at = time.Now().Add(-time.Second)
+		if lines := p.lines(wa, width); len(lines) == 0 {
+			t.Fatalf("lines(%d) returned nothing", width)
+		}
+	}
+}
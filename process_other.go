@@ -0,0 +1,10 @@
+//go:build !linux
+
+package peek
+
+// Process adds a panel with process stats, it's only supported on linux.
+func Process() {
+	panels.Set("process", &panel{lines: func(wa *Watcher, width int) []string {
+		return []string{wa.row("process", "only supported on linux", "")}
+	}})
+}
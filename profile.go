@@ -0,0 +1,227 @@
+package peek
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Just enough of a profile.proto decoder to get the flat top functions out of a pprof file,
+// so there is no need to vendor github.com/google/pprof.
+
+var errProto = errors.New("malformed profile")
+
+type protoField struct {
+	num  int
+	wire int
+	v    uint64
+	b    []byte
+}
+
+// protoFields splits a protobuf message into its fields.
+func protoFields(b []byte) ([]protoField, error) {
+	var out []protoField
+	for len(b) > 0 {
+		key, n := protoVarint(b)
+		if n == 0 {
+			return nil, errProto
+		}
+		b = b[n:]
+		f := protoField{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case 0:
+			f.v, n = protoVarint(b)
+			if n == 0 {
+				return nil, errProto
+			}
+			b = b[n:]
+		case 1:
+			if len(b) < 8 {
+				return nil, errProto
+			}
+			b = b[8:]
+		case 2:
+			l, n := protoVarint(b)
+			if n == 0 || uint64(len(b)-n) < l {
+				return nil, errProto
+			}
+			f.b = b[n : n+int(l)]
+			b = b[n+int(l):]
+		case 5:
+			if len(b) < 4 {
+				return nil, errProto
+			}
+			b = b[4:]
+		default:
+			return nil, errProto
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func protoVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// protoInts returns the values of a repeated integer field, packed or not.
+func protoInts(f protoField) []uint64 {
+	if f.wire == 0 {
+		return []uint64{f.v}
+	}
+	var out []uint64
+	for b := f.b; len(b) > 0; {
+		v, n := protoVarint(b)
+		if n == 0 {
+			break
+		}
+		out = append(out, v)
+		b = b[n:]
+	}
+	return out
+}
+
+// topFunctions returns the n functions with the highest flat value of the last sample type,
+// that's cpu time for CPU profiles and in use bytes for heap profiles.
+func topFunctions(data []byte, n int) ([]string, error) {
+	if zr, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	fields, err := protoFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var strs []string
+	var unit uint64
+	var samples [][]byte
+	locFunc := map[uint64]uint64{}
+	funcName := map[uint64]uint64{}
+	for _, f := range fields {
+		switch f.num {
+		case 1: // sample_type, the unit of the last one is used
+			sub, err := protoFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range sub {
+				if s.num == 2 {
+					unit = s.v
+				}
+			}
+		case 2:
+			samples = append(samples, f.b)
+		case 4: // location, its first line is the leaf when functions were inlined
+			sub, err := protoFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			var id uint64
+			var fn uint64
+			for _, s := range sub {
+				switch {
+				case s.num == 1:
+					id = s.v
+				case s.num == 4 && fn == 0:
+					line, err := protoFields(s.b)
+					if err != nil {
+						return nil, err
+					}
+					for _, l := range line {
+						if l.num == 1 {
+							fn = l.v
+						}
+					}
+				}
+			}
+			locFunc[id] = fn
+		case 5:
+			sub, err := protoFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			var id, name uint64
+			for _, s := range sub {
+				switch s.num {
+				case 1:
+					id = s.v
+				case 2:
+					name = s.v
+				}
+			}
+			funcName[id] = name
+		case 6:
+			strs = append(strs, string(f.b))
+		}
+	}
+	str := func(i uint64) string {
+		if i < uint64(len(strs)) {
+			return strs[i]
+		}
+		return "?"
+	}
+
+	flat := map[string]int64{}
+	var total int64
+	for _, s := range samples {
+		sub, err := protoFields(s)
+		if err != nil {
+			return nil, err
+		}
+		var locs, values []uint64
+		for _, f := range sub {
+			switch f.num {
+			case 1:
+				locs = append(locs, protoInts(f)...)
+			case 2:
+				values = append(values, protoInts(f)...)
+			}
+		}
+		if len(locs) == 0 || len(values) == 0 {
+			continue
+		}
+		v := int64(values[len(values)-1])
+		flat[str(funcName[locFunc[locs[0]]])] += v
+		total += v
+	}
+
+	names := make([]string, 0, len(flat))
+	for name := range flat {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return flat[names[i]] > flat[names[j]] })
+	if len(names) > n {
+		names = names[:n]
+	}
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		out = append(out, fmt.Sprintf("%5.1f%% %10s  %s", float64(flat[name])/float64(total)*100, profileValue(flat[name], str(unit)), name))
+	}
+	if len(out) == 0 {
+		out = append(out, "no samples")
+	}
+	return out, nil
+}
+
+func profileValue(v int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return roundDuration(time.Duration(v)).String()
+	case "bytes":
+		return humanBytes(float64(v))
+	}
+	return fmt.Sprint(v)
+}
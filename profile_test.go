@@ -0,0 +1,50 @@
+package peek
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+var profileSink [][]byte
+
+//go:noinline
+func allocateForProfile() {
+	for i := 0; i < 64; i++ {
+		profileSink = append(profileSink, make([]byte, 1<<20))
+	}
+}
+
+func TestTopFunctionsHeap(t *testing.T) {
+	defer func(rate int) { runtime.MemProfileRate = rate }(runtime.MemProfileRate)
+	runtime.MemProfileRate = 1
+	allocateForProfile()
+	defer func() { profileSink = nil }()
+	// the heap profile is as of the last GC
+	runtime.GC()
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	top, err := topFunctions(buf.Bytes(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) == 0 || len(top) > 10 {
+		t.Fatalf("got %d functions, want 1 to 10", len(top))
+	}
+	if !strings.Contains(top[0], "allocateForProfile") || !strings.Contains(top[0], "MiB") {
+		t.Fatalf("top function is %q, want allocateForProfile in MiB:\n%s", top[0], strings.Join(top, "\n"))
+	}
+}
+
+func TestTopFunctionsMalformed(t *testing.T) {
+	for _, b := range [][]byte{{0x0a}, {0x0a, 0x05, 0x01}, {0xff}} {
+		if _, err := topFunctions(b, 10); err == nil {
+			t.Errorf("topFunctions(%x) didn't fail", b)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package peek
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// WarnFull makes a Chan or Pool entry flash and log a warning when it stays full for d.
+func WarnFull(d time.Duration) EntryOption {
+	return optionFunc(func(e *entry) { e.warnFull = d })
+}
+
+// warnWhenFull adds the WarnFull rule, full reports if the entry is full.
+func (e *entry) warnWhenFull(full func(v any) bool) {
+	if e.warnFull > 0 {
+		r := When(full).For(e.warnFull).Flash().Log()
+		r.alert = true
+		r.apply(e)
+	}
+}
+
+// Chan adds a channel of any type, showing its len/cap with a fill bar.
+func Chan(desc string, ch any, opts ...EntryOption) {
+	c := reflect.ValueOf(ch)
+	if c.Kind() != reflect.Chan {
+		panic(fmt.Sprintf("peek.Chan: %T is not a channel", ch))
+	}
+	e := &entry{get: func() any { return c.Len() }}
+	e.draw = func(e *entry, v any, width int) string {
+		text := fmt.Sprintf(" %d/%d", v, c.Cap())
+		if c.Cap() == 0 {
+			return "unbuffered" + text
+		}
+		return bar(float64(v.(int))/float64(c.Cap()), width-len(text)) + text
+	}
+	e.with(opts)
+	e.warnWhenFull(func(v any) bool { return c.Cap() > 0 && v.(int) >= c.Cap() })
+	funcs.Set(desc, e)
+}
+
+// WorkerPool tracks how busy a pool of workers is, see Pool.
+type WorkerPool struct {
+	workers int64
+	active  int64
+	queued  int64
+}
+
+// Pool adds a worker pool of the given size, showing active, idle and queued jobs.
+// Call Queued when a job is submitted and Track (or Started and Finished) when a worker runs it.
+func Pool(desc string, workers int, opts ...EntryOption) *WorkerPool {
+	p := &WorkerPool{workers: int64(workers)}
+	e := &entry{get: func() any { return atomic.LoadInt64(&p.active) }}
+	e.draw = func(e *entry, v any, width int) string {
+		workers := atomic.LoadInt64(&p.workers)
+		active := v.(int64)
+		text := fmt.Sprintf(" active %d idle %d queued %d", active, workers-active, atomic.LoadInt64(&p.queued))
+		if workers == 0 {
+			return text
+		}
+		return bar(float64(active)/float64(workers), width-len(text)) + text
+	}
+	e.with(opts)
+	e.warnWhenFull(func(v any) bool { return v.(int64) >= atomic.LoadInt64(&p.workers) })
+	funcs.Set(desc, e)
+	return p
+}
+
+// SetWorkers changes the pool size.
+func (p *WorkerPool) SetWorkers(n int) {
+	atomic.StoreInt64(&p.workers, int64(n))
+}
+
+// Queued records a job waiting for a worker.
+func (p *WorkerPool) Queued() {
+	atomic.AddInt64(&p.queued, 1)
+}
+
+// Started records a worker picking up a queued job.
+func (p *WorkerPool) Started() {
+	// jobs that weren't queued first don't make queued negative
+	for {
+		q := atomic.LoadInt64(&p.queued)
+		if q <= 0 || atomic.CompareAndSwapInt64(&p.queued, q, q-1) {
+			break
+		}
+	}
+	atomic.AddInt64(&p.active, 1)
+}
+
+// Finished records a worker going idle.
+func (p *WorkerPool) Finished() {
+	atomic.AddInt64(&p.active, -1)
+}
+
+// Track runs a queued job, recording its start and finish.
+func (p *WorkerPool) Track(fn func()) {
+	p.Started()
+	defer p.Finished()
+	fn()
+}
@@ -0,0 +1,75 @@
+package peek
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChan(t *testing.T) {
+	ch := make(chan int, 4)
+	Chan("test chan", ch, WarnFull(time.Second))
+	defer funcs.Delete("test chan")
+	e := funcs.Get("test chan")
+
+	ch <- 1
+	ch <- 2
+	if got := e.draw(e, e.get(), 30); !strings.HasSuffix(got, " 2/4") || !strings.HasPrefix(got, "[") {
+		t.Errorf("draw = %q, want a half full bar and 2/4", got)
+	}
+	if len(e.rules) != 1 {
+		t.Fatalf("WarnFull added %d rules, want 1", len(e.rules))
+	}
+	full := e.rules[0]
+	now := time.Now()
+	if full.check(&Watcher{}, "ch", e.get(), 0, now) {
+		t.Error("WarnFull matched a channel that isn't full")
+	}
+	ch <- 3
+	ch <- 4
+	if full.check(&Watcher{}, "ch", e.get(), 0, now) || !full.check(&Watcher{}, "ch", e.get(), 0, now.Add(time.Second)) {
+		t.Error("WarnFull didn't wait for the channel to stay full")
+	}
+
+	Chan("test unbuffered", make(chan struct{}))
+	defer funcs.Delete("test unbuffered")
+	u := funcs.Get("test unbuffered")
+	if got := u.draw(u, u.get(), 30); got != "unbuffered 0/0" {
+		t.Errorf("draw = %q", got)
+	}
+}
+
+func TestChanNotAChannel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Chan accepted an int")
+		}
+	}()
+	Chan("test not chan", 1)
+}
+
+func TestPool(t *testing.T) {
+	p := Pool("test pool", 4)
+	defer funcs.Delete("test pool")
+	e := funcs.Get("test pool")
+
+	p.Queued()
+	p.Queued()
+	p.Started()
+	// a job that was never queued doesn't make queued negative
+	p.Started()
+	p.Started()
+	if got := e.draw(e, e.get(), 60); !strings.HasSuffix(got, " active 3 idle 1 queued 0") {
+		t.Errorf("draw = %q", got)
+	}
+	p.Finished()
+	p.Track(func() {
+		if got := e.get(); got != int64(3) {
+			t.Errorf("active while tracking = %v, want 3", got)
+		}
+	})
+	p.SetWorkers(0)
+	if got := e.draw(e, e.get(), 60); strings.Contains(got, "[") {
+		t.Errorf("draw = %q, want no bar for a pool without workers", got)
+	}
+}
@@ -0,0 +1,130 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Rule changes the colour of an entry and/or raises an alert when its condition is met.
+// Rules are passed to Var and Func, e.g.
+//
+//	peek.Var("queue: ", &n, peek.Above(1000).Colour(peek.RedBold).Bell())
+//	peek.Var("done: ", &done, peek.Stalled().For(5*time.Second).Colour(peek.YellowBold).Log())
+type Rule struct {
+	cond     func(v any, rate float64) bool
+	colour   string
+	hold     time.Duration
+	flash    bool
+	bell     bool
+	log      bool
+	callback func(desc string, v any)
+	// alert uses the theme alert colour
+	alert bool
+
+	// since is when the condition started to hold, zero if it doesn't
+	since time.Time
+	fired bool
+}
+
+// When creates a rule that matches when cond returns true for the current value.
+func When(cond func(v any) bool) *Rule {
+	return &Rule{cond: func(v any, _ float64) bool { return cond(v) }}
+}
+
+// Above matches numeric values greater than x.
+func Above(x float64) *Rule {
+	return &Rule{cond: func(v any, _ float64) bool {
+		f, ok := toFloat(v)
+		return ok && f > x
+	}}
+}
+
+// Below matches numeric values lower than x.
+func Below(x float64) *Rule {
+	return &Rule{cond: func(v any, _ float64) bool {
+		f, ok := toFloat(v)
+		return ok && f < x
+	}}
+}
+
+// Rate matches when cond returns true for the rate of change of the value, per second.
+// It never matches values that aren't numbers, nor during the first second, before there is a rate.
+func Rate(cond func(perSec float64) bool) *Rule {
+	return &Rule{cond: func(_ any, rate float64) bool { return !math.IsNaN(rate) && cond(rate) }}
+}
+
+// Stalled matches when the value stops changing, usually combined with For.
+func Stalled() *Rule {
+	return Rate(func(perSec float64) bool { return perSec == 0 })
+}
+
+// Colour overrides the value colour of the entry while the rule matches.
+func (r *Rule) Colour(c string) *Rule {
+	r.colour = c
+	return r
+}
+
+// For makes the rule match only after the condition held for at least d.
+func (r *Rule) For(d time.Duration) *Rule {
+	r.hold = d
+	return r
+}
+
+// Flash makes the entry blink while the rule matches.
+func (r *Rule) Flash() *Rule {
+	r.flash = true
+	return r
+}
+
+// Bell rings the terminal bell when the rule starts matching.
+func (r *Rule) Bell() *Rule {
+	r.bell = true
+	return r
+}
+
+// Log appends a marked line to the log pane when the rule starts matching.
+func (r *Rule) Log() *Rule {
+	r.log = true
+	return r
+}
+
+// Call runs fn in its own goroutine when the rule starts matching.
+func (r *Rule) Call(fn func(desc string, v any)) *Rule {
+	r.callback = fn
+	return r
+}
+
+// apply attaches a copy of the rule, so one rule can be shared by many entries.
+func (r *Rule) apply(e *entry) {
+	c := *r
+	e.rules = append(e.rules, &c)
+}
+
+// check evaluates the rule and fires its alerts on the first match.
+func (r *Rule) check(wa *Watcher, desc string, v any, rate float64, now time.Time) bool {
+	if !r.cond(v, rate) {
+		r.since = time.Time{}
+		r.fired = false
+		return false
+	}
+	if r.since.IsZero() {
+		r.since = now
+	}
+	if now.Sub(r.since) < r.hold {
+		return false
+	}
+	if !r.fired {
+		r.fired = true
+		if r.bell {
+			wa.bell = true
+		}
+		if r.log {
+			wa.log(fmt.Sprintf("!! %s%v", desc, v))
+		}
+		if r.callback != nil {
+			go r.callback(desc, v)
+		}
+	}
+	return true
+}
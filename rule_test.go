@@ -0,0 +1,108 @@
+package peek
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRuleStates(t *testing.T) {
+	start := time.Now()
+	type step struct {
+		at    time.Duration
+		v     any
+		match bool
+		fire  bool
+	}
+	for _, tc := range []struct {
+		name  string
+		rule  *Rule
+		steps []step
+	}{
+		{"fires once while matching", Above(10), []step{
+			{0, 5, false, false},
+			{time.Second, 11, true, true},
+			{2 * time.Second, 12, true, false},
+		}},
+		{"resets when the condition stops", Above(10), []step{
+			{0, 11, true, true},
+			{time.Second, 9, false, false},
+			{2 * time.Second, 11, true, true},
+		}},
+		{"For waits for the condition to hold", Below(0).For(3 * time.Second), []step{
+			{0, -1, false, false},
+			{2 * time.Second, -1, false, false},
+			{3 * time.Second, -1, true, true},
+			{4 * time.Second, -1, true, false},
+		}},
+		{"For restarts after a break", Below(0).For(2 * time.Second), []step{
+			{0, -1, false, false},
+			{time.Second, 1, false, false},
+			{2 * time.Second, -1, false, false},
+			{4 * time.Second, -1, true, true},
+		}},
+		{"non numbers don't match numeric rules", Above(0), []step{
+			{0, "100", false, false},
+		}},
+		{"When", When(func(v any) bool { return v == "down" }), []step{
+			{0, "up", false, false},
+			{time.Second, "down", true, true},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := tc.rule
+			wa := &Watcher{}
+			for i, s := range tc.steps {
+				was := r.fired
+				if got := r.check(wa, "x", s.v, math.NaN(), start.Add(s.at)); got != s.match {
+					t.Fatalf("step %d: match = %v, want %v", i, got, s.match)
+				}
+				if fire := r.fired && !was; fire != s.fire {
+					t.Fatalf("step %d: fired = %v, want %v", i, fire, s.fire)
+				}
+			}
+		})
+	}
+}
+
+func TestRateRules(t *testing.T) {
+	for _, tc := range []struct {
+		rule *Rule
+		rate float64
+		want bool
+	}{
+		{Stalled(), 0, true},
+		{Stalled(), 2, false},
+		{Stalled(), math.NaN(), false},
+		{Rate(func(r float64) bool { return r > 1 }), 2, true},
+		{Rate(func(r float64) bool { return true }), math.NaN(), false},
+	} {
+		if got := tc.rule.check(&Watcher{}, "x", 1, tc.rate, time.Now()); got != tc.want {
+			t.Errorf("check with rate %v = %v, want %v", tc.rate, got, tc.want)
+		}
+	}
+}
+
+func TestStalledWaitsForRate(t *testing.T) {
+	wa := &Watcher{}
+	e := (&entry{}).with([]EntryOption{Stalled().Log()})
+	n := 1
+	e.get = func() any { return n }
+	now := time.Now()
+	wa.line("ctr", e, now, false)
+	if len(wa.lines) != 0 {
+		t.Fatalf("logged %q before there was a rate", wa.lines[0].text)
+	}
+	wa.line("ctr", e, now.Add(time.Second), false)
+	if len(wa.lines) != 1 {
+		t.Fatalf("logged %d lines once stalled, want 1", len(wa.lines))
+	}
+
+	s := (&entry{}).with([]EntryOption{Stalled().Log()})
+	s.get = func() any { return "text" }
+	wa.line("s", s, now, false)
+	wa.line("s", s, now.Add(2*time.Second), false)
+	if len(wa.lines) != 1 {
+		t.Fatalf("Stalled matched a value that isn't a number")
+	}
+}
@@ -0,0 +1,145 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// runtime/metrics names, pauses moved in go1.22 so the old name is a fallback
+var runtimeMetrics = []string{
+	"/sched/goroutines:goroutines",
+	"/memory/classes/heap/objects:bytes",
+	"/memory/classes/heap/unused:bytes",
+	"/memory/classes/heap/released:bytes",
+	"/gc/cycles/total:gc-cycles",
+	"/gc/heap/allocs:bytes",
+	"/sched/pauses/total/gc:seconds",
+	"/gc/pauses:seconds",
+	"/sched/latencies:seconds",
+}
+
+// Runtime adds a panel with goroutines, heap, GC and scheduler stats.
+// It's built on runtime/metrics, so unlike runtime.ReadMemStats it doesn't stop the world.
+func Runtime() {
+	r := &runtimePanel{
+		samples:    make([]metrics.Sample, len(runtimeMetrics)),
+		goroutines: newHistory(sparkWidth),
+		heap:       newHistory(sparkWidth),
+		allocRate:  newHistory(sparkWidth),
+	}
+	for i, name := range runtimeMetrics {
+		r.samples[i].Name = name
+	}
+	panels.Set("runtime", &panel{lines: r.lines})
+}
+
+// sparkWidth is how many samples, one per second, sparklines keep.
+const sparkWidth = 60
+
+type runtimePanel struct {
+	mu      sync.Mutex
+	samples []metrics.Sample
+	at      time.Time
+	allocs  float64
+
+	goroutines *history
+	heap       *history
+	allocRate  *history
+}
+
+func (r *runtimePanel) lines(wa *Watcher, width int) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	metrics.Read(r.samples)
+	v := map[string]metrics.Value{}
+	for _, s := range r.samples {
+		v[s.Name] = s.Value
+	}
+
+	goroutines := metricFloat(v["/sched/goroutines:goroutines"])
+	inUse := metricFloat(v["/memory/classes/heap/objects:bytes"]) + metricFloat(v["/memory/classes/heap/unused:bytes"])
+	allocs := metricFloat(v["/gc/heap/allocs:bytes"])
+	pauses := v["/sched/pauses/total/gc:seconds"]
+	if pauses.Kind() != metrics.KindFloat64Histogram {
+		pauses = v["/gc/pauses:seconds"]
+	}
+
+	// histories are pushed once a second no matter how often the screen is drawn
+	now := time.Now()
+	if dt := now.Sub(r.at); dt >= time.Second {
+		if !r.at.IsZero() {
+			r.allocRate.push((allocs - r.allocs) / dt.Seconds())
+		}
+		r.goroutines.push(goroutines)
+		r.heap.push(inUse)
+		r.allocs, r.at = allocs, now
+	}
+	rate := 0.0
+	if vals := r.allocRate.values(); len(vals) > 0 {
+		rate = vals[len(vals)-1]
+	}
+
+	spark := width - 32
+	return []string{
+		wa.row("goroutines", fmt.Sprintf("%.0f", goroutines), sparkline(r.goroutines.values(), spark)),
+		wa.row("heap in use", humanBytes(inUse), sparkline(r.heap.values(), spark)),
+		wa.row("heap released", humanBytes(metricFloat(v["/memory/classes/heap/released:bytes"])), ""),
+		wa.row("alloc rate", humanBytes(rate)+"/s", sparkline(r.allocRate.values(), spark)),
+		wa.row("gc cycles", fmt.Sprintf("%.0f", metricFloat(v["/gc/cycles/total:gc-cycles"])), ""),
+		wa.row("gc pause", percentiles(pauses), ""),
+		wa.row("sched latency", percentiles(v["/sched/latencies:seconds"]), ""),
+		wa.row("GOMAXPROCS", fmt.Sprint(runtime.GOMAXPROCS(0)), ""),
+	}
+}
+
+func metricFloat(v metrics.Value) float64 {
+	switch v.Kind() {
+	case metrics.KindUint64:
+		return float64(v.Uint64())
+	case metrics.KindFloat64:
+		return v.Float64()
+	}
+	return 0
+}
+
+// percentiles formats p50/p99/max of a runtime/metrics histogram of seconds.
+func percentiles(v metrics.Value) string {
+	if v.Kind() != metrics.KindFloat64Histogram {
+		return "n/a"
+	}
+	h := v.Float64Histogram()
+	return fmt.Sprintf("p50 %s p99 %s max %s",
+		seconds(histQuantile(h, 0.5)), seconds(histQuantile(h, 0.99)), seconds(histQuantile(h, 1)))
+}
+
+// histQuantile returns the upper bound of the bucket holding quantile q.
+func histQuantile(h *metrics.Float64Histogram, q float64) float64 {
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(q * float64(total)))
+	var sum uint64
+	for i, c := range h.Counts {
+		sum += c
+		if sum >= target && c > 0 {
+			// the last bucket is unbounded, use its lower bound
+			if math.IsInf(h.Buckets[i+1], 1) {
+				return h.Buckets[i]
+			}
+			return h.Buckets[i+1]
+		}
+	}
+	return 0
+}
+
+func seconds(f float64) string {
+	return time.Duration(f * float64(time.Second)).Round(time.Microsecond).String()
+}
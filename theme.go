@@ -0,0 +1,312 @@
+package peek
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ColourMode is how many colours the terminal can show.
+type ColourMode int
+
+const (
+	// NoColour strips all colours, used for NO_COLOR and dumb terminals.
+	// Attributes like reverse video stay, they mark the selected Var and the PAUSED banner.
+	NoColour ColourMode = iota
+	// Colour16 uses the basic 16 ANSI colours.
+	Colour16
+	// Colour256 uses the xterm 256 colour palette.
+	Colour256
+	// TrueColour uses 24-bit RGB colours.
+	TrueColour
+)
+
+// Theme sets the colours of every part of the dashboard.
+// Each colour is a space separated list of a colour and attributes, e.g. "#ff8800 bold".
+// Colours can be "#rgb", "#rrggbb", "rgb(r,g,b)", a 256 palette index like "208"
+// or a name: black, red, green, yellow, blue, magenta, cyan, white, optionally prefixed with "bright-".
+// Attributes are bold, faint, italic, underline and reverse.
+// Raw escape sequences like peek.RedBold work too.
+type Theme struct {
+	Desc   string `json:"desc"`
+	Value  string `json:"value"`
+	Log    string `json:"log"`
+	Border string `json:"border"`
+	Header string `json:"header"`
+	Alert  string `json:"alert"`
+	Prompt string `json:"prompt"`
+	Faint  string `json:"faint"`
+	Debug  string `json:"debug"`
+	Info   string `json:"info"`
+	Warn   string `json:"warn"`
+	Error  string `json:"error"`
+}
+
+// DarkTheme is the default theme, for terminals with a dark background.
+var DarkTheme = Theme{
+	Desc:   "green bold",
+	Value:  "blue bold",
+	Log:    "white bold",
+	Border: "white",
+	Header: "green bold",
+	Alert:  "red bold",
+	Prompt: "yellow bold",
+	Faint:  "faint",
+	Debug:  "faint",
+	Info:   "green bold",
+	Warn:   "yellow bold",
+	Error:  "red bold",
+}
+
+// LightTheme is for terminals with a light background.
+var LightTheme = Theme{
+	Desc:   "#005f00 bold",
+	Value:  "#00005f bold",
+	Log:    "#262626",
+	Border: "#8a8a8a",
+	Header: "#005f87 bold",
+	Alert:  "#af0000 bold",
+	Prompt: "#875f00 bold",
+	Faint:  "#8a8a8a",
+	Debug:  "#8a8a8a",
+	Info:   "#005f00",
+	Warn:   "#875f00 bold",
+	Error:  "#af0000 bold",
+}
+
+// LoadTheme reads a theme from a JSON file with the same keys as Theme,
+// missing keys are taken from DarkTheme.
+func LoadTheme(path string) (Theme, error) {
+	t := DarkTheme
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, fmt.Errorf("theme %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// DetectColourMode guesses what the terminal supports from NO_COLOR, TERM and COLORTERM.
+func DetectColourMode() ColourMode {
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColour
+	}
+	term := os.Getenv("TERM")
+	switch ct := os.Getenv("COLORTERM"); {
+	case term == "dumb":
+		return NoColour
+	case ct == "truecolor" || ct == "24bit":
+		return TrueColour
+	case strings.Contains(term, "256color"):
+		return Colour256
+	}
+	return Colour16
+}
+
+// SetTheme sets the colours of the dashboard, downgraded to what the terminal supports.
+// It takes effect on the next frame, use WithTheme to set it before the first one.
+func (wa *Watcher) SetTheme(t Theme) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.theme = t
+	wa.themeChanged = true
+	wa.notify()
+}
+
+// SetColourMode overrides the detected terminal colour support.
+// It takes effect on the next frame, use WithColourMode to set it before the first one.
+func (wa *Watcher) SetColourMode(m ColourMode) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.colourMode = m
+	wa.themeChanged = true
+	wa.notify()
+}
+
+// frameColours applies a theme changed since the last frame, it runs on the render goroutine
+// before drawing, so the colours are only written by the goroutine reading them.
+// It reports if colours are off.
+func (wa *Watcher) frameColours() (noColour bool) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	if wa.themeChanged {
+		wa.applyTheme()
+		wa.themeChanged = false
+	}
+	return wa.colourMode == NoColour
+}
+
+// applyTheme resolves the theme to escape sequences, wa.mu must be held.
+// Other than in Create it must only run on the render goroutine, see frameColours.
+func (wa *Watcher) applyTheme() {
+	c := func(spec string) string { return colour(spec, wa.colourMode) }
+	t := wa.theme
+	wa.descColour, wa.valueColour, wa.logColour = c(t.Desc), c(t.Value), c(t.Log)
+	wa.borderColour, wa.headerColour = c(t.Border), c(t.Header)
+	wa.alertColour, wa.promptColour, wa.faintColour = c(t.Alert), c(t.Prompt), c(t.Faint)
+	wa.levelColours = map[string]string{"debug": c(t.Debug), "info": c(t.Info), "warn": c(t.Warn), "error": c(t.Error)}
+}
+
+var (
+	basicColours = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+	attributes   = map[string]int{"bold": 1, "faint": 2, "italic": 3, "underline": 4, "reverse": 7}
+	rgbFunc      = regexp.MustCompile(`^rgb\((\d+),\s*(\d+),\s*(\d+)\)$`)
+	rgbArgs      = regexp.MustCompile(`rgb\([^)]*\)`)
+	sgr          = regexp.MustCompile("\033\\[[0-9;]*m")
+)
+
+// colour turns a colour spec into an escape sequence for mode.
+func colour(spec string, mode ColourMode) string {
+	if mode == NoColour || spec == "" {
+		return ""
+	}
+	if strings.HasPrefix(spec, "\033") {
+		return spec
+	}
+	var codes []string
+	// rgb(r, g, b) may contain spaces, so it's pulled out first
+	spec = rgbArgs.ReplaceAllStringFunc(strings.TrimSpace(spec), func(s string) string {
+		return strings.ReplaceAll(s, " ", "")
+	})
+	for _, tok := range strings.Fields(spec) {
+		if a, ok := attributes[tok]; ok {
+			codes = append(codes, strconv.Itoa(a))
+			continue
+		}
+		if r, g, b, ok := parseRGB(tok); ok {
+			codes = append(codes, rgbCode(r, g, b, mode))
+			continue
+		}
+		if n, err := strconv.Atoi(tok); err == nil && n >= 0 && n < 256 {
+			if mode == Colour16 {
+				r, g, b := xtermRGB(n)
+				codes = append(codes, rgbCode(r, g, b, mode))
+			} else {
+				codes = append(codes, fmt.Sprintf("38;5;%d", n))
+			}
+			continue
+		}
+		name := strings.TrimPrefix(tok, "bright-")
+		for i, c := range basicColours {
+			if c == name {
+				if name != tok {
+					i += 60
+				}
+				codes = append(codes, strconv.Itoa(30+i))
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(codes, ";") + "m"
+}
+
+func parseRGB(s string) (r, g, b uint8, ok bool) {
+	if m := rgbFunc.FindStringSubmatch(s); m != nil {
+		var c [3]uint8
+		for i := range c {
+			n, err := strconv.Atoi(m[i+1])
+			if err != nil || n > 255 {
+				return 0, 0, 0, false
+			}
+			c[i] = uint8(n)
+		}
+		return c[0], c[1], c[2], true
+	}
+	if !strings.HasPrefix(s, "#") {
+		return 0, 0, 0, false
+	}
+	s = s[1:]
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || len(s) != 6 {
+		return 0, 0, 0, false
+	}
+	return uint8(n >> 16), uint8(n >> 8), uint8(n), true
+}
+
+// rgbCode returns the SGR code for a colour, downgraded to the nearest one mode supports.
+func rgbCode(r, g, b uint8, mode ColourMode) string {
+	switch mode {
+	case TrueColour:
+		return fmt.Sprintf("38;2;%d;%d;%d", r, g, b)
+	case Colour256:
+		return fmt.Sprintf("38;5;%d", nearest(r, g, b, 16, 256))
+	}
+	n := nearest(r, g, b, 0, 16)
+	if n >= 8 {
+		return strconv.Itoa(90 + n - 8)
+	}
+	return strconv.Itoa(30 + n)
+}
+
+// nearest returns the xterm palette index in [from, to) closest to the colour.
+func nearest(r, g, b uint8, from, to int) int {
+	best, bestDist := from, -1
+	for i := from; i < to; i++ {
+		pr, pg, pb := xtermRGB(i)
+		dr, dg, db := int(r)-int(pr), int(g)-int(pg), int(b)-int(pb)
+		if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// xtermRGB returns the colour of an xterm 256 palette index.
+func xtermRGB(n int) (uint8, uint8, uint8) {
+	switch {
+	case n < 16:
+		basic := [16][3]uint8{
+			{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+			{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+		}
+		return basic[n][0], basic[n][1], basic[n][2]
+	case n < 232:
+		n -= 16
+		level := func(i int) uint8 {
+			if i == 0 {
+				return 0
+			}
+			return uint8(55 + i*40)
+		}
+		return level(n / 36), level(n / 6 % 6), level(n % 6)
+	}
+	grey := uint8(8 + (n-232)*10)
+	return grey, grey, grey
+}
+
+// stripColours removes the colours from SGR sequences, used when colours are off.
+// Attributes like bold and reverse video are kept.
+func stripColours(s string) string {
+	return sgr.ReplaceAllStringFunc(s, func(seq string) string {
+		params := strings.Split(seq[2:len(seq)-1], ";")
+		var keep []string
+		for i := 0; i < len(params); i++ {
+			switch n, _ := strconv.Atoi(params[i]); {
+			case n == 38 || n == 48 || n == 58:
+				// extended colours are followed by 5;n or 2;r;g;b
+				if i+1 < len(params) && params[i+1] == "5" {
+					i += 2
+				} else if i+1 < len(params) && params[i+1] == "2" {
+					i += 4
+				}
+			case n >= 30 && n <= 49, n >= 90 && n <= 107:
+			default:
+				keep = append(keep, params[i])
+			}
+		}
+		if len(keep) == 0 {
+			return ""
+		}
+		return "\033[" + strings.Join(keep, ";") + "m"
+	})
+}
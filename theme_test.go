@@ -0,0 +1,90 @@
+package peek
+
+import (
+	"testing"
+	"time"
+)
+
+func TestColour(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		mode ColourMode
+		want string
+	}{
+		{"red bold", Colour16, "\033[31;1m"},
+		{"bright-blue", Colour16, "\033[94m"},
+		{"#ff8800", TrueColour, "\033[38;2;255;136;0m"},
+		{"rgb(255, 136, 0) underline", TrueColour, "\033[38;2;255;136;0;4m"},
+		{"#f80", Colour256, "\033[38;5;208m"},
+		{"208", Colour256, "\033[38;5;208m"},
+		{"#ff0000", Colour16, "\033[91m"},
+		{"red bold", NoColour, ""},
+		{RedBold, TrueColour, RedBold},
+		{"nonsense", TrueColour, ""},
+	} {
+		if got := colour(tc.spec, tc.mode); got != tc.want {
+			t.Errorf("colour(%q, %d) = %q, want %q", tc.spec, tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestParseRGB(t *testing.T) {
+	for _, tc := range []struct {
+		s       string
+		r, g, b uint8
+		ok      bool
+	}{
+		{"#102030", 0x10, 0x20, 0x30, true},
+		{"#abc", 0xaa, 0xbb, 0xcc, true},
+		{"rgb(1,2,3)", 1, 2, 3, true},
+		{"rgb(1,2,300)", 0, 0, 0, false},
+		{"#12345", 0, 0, 0, false},
+		{"#ggg", 0, 0, 0, false},
+		{"red", 0, 0, 0, false},
+	} {
+		r, g, b, ok := parseRGB(tc.s)
+		if r != tc.r || g != tc.g || b != tc.b || ok != tc.ok {
+			t.Errorf("parseRGB(%q) = %d %d %d %v, want %d %d %d %v", tc.s, r, g, b, ok, tc.r, tc.g, tc.b, tc.ok)
+		}
+	}
+}
+
+func TestStripColours(t *testing.T) {
+	for _, tc := range []struct {
+		s, want string
+	}{
+		{RedBold + "a" + Reset, "\033[01ma" + Reset},
+		{"\033[7mselected", "\033[7mselected"},
+		{"\033[38;2;1;2;3;7mx", "\033[7mx"},
+		{"\033[38;5;208;48;5;16mx", "x"},
+		{"\033[31;4mx", "\033[4mx"},
+		{"plain", "plain"},
+	} {
+		if got := stripColours(tc.s); got != tc.want {
+			t.Errorf("stripColours(%q) = %q, want %q", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestSetThemeWhileDrawing(t *testing.T) {
+	wa := &Watcher{theme: DarkTheme, colourMode: Colour16, cols: 80}
+	wa.applyTheme()
+	n := 1
+	e := &entry{get: func() any { return n }}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			wa.SetTheme(LightTheme)
+			wa.SetColourMode(TrueColour)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		wa.frameColours()
+		wa.line("n", e, time.Now(), false)
+	}
+	<-done
+	if wa.frameColours(); wa.descColour != colour(LightTheme.Desc, TrueColour) {
+		t.Fatalf("desc colour = %q, want the light theme", wa.descColour)
+	}
+}
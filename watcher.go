@@ -3,8 +3,9 @@ package peek
 import (
 	"fmt"
 	"os"
-	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/main-kube/util/safe"
@@ -38,50 +39,108 @@ type Watcher struct {
 	descColour  string
 	valueColour string
 	logColour   string
-	hight       uint16
-	width       uint16
-	buff        string
-	b           []byte
-	c           chan struct{}
+
+	theme        Theme
+	colourMode   ColourMode
+	themeChanged bool
+	borderColour string
+	headerColour string
+	alertColour  string
+	promptColour string
+	faintColour  string
+	levelColours map[string]string
+	hight        uint16
+	width        uint16
+	c            chan struct{}
+	mu           sync.Mutex
+	bell         bool
+	interactive  bool
+	closed       bool
+	restore      func()
+	cols         int
+	mode         int
+	cursor       int
+	esc          int
+	input        string
+	view         view
+	lines        []logLine
+	lastAt       time.Time
+	captures     []*capture
+	timeFormat   TimeFormat
+	noWrap       bool
+	include      *regexp.Regexp
+	exclude      *regexp.Regexp
+	highlights   []highlight
+	rawJSON      bool
+	jsonFields   []string
 
 	whHardSet bool
 }
 
 var (
-	vars       = safe.SortedMap[string, any]{}
-	funcs      = safe.SortedMap[string, func() any]{}
-	logFile, _ = os.Create("/tmp/peek-var/log.txt")
+	vars  = safe.SortedMap[string, *entry]{}
+	funcs = safe.SortedMap[string, *entry]{}
 )
 
-func Create(interval time.Duration) *Watcher {
-	os.MkdirAll("/tmp/peek-var", 0755)
+// Create starts the dashboard, see Option for what can be configured.
+// PEEK_* environment variables override the options, so it can be reconfigured without recompiling.
+func Create(opts ...Option) *Watcher {
+	cfg := &config{
+		interval:   DefaultInterval,
+		theme:      DarkTheme,
+		colourMode: DetectColourMode(),
+		log:        LogConfig{Path: DefaultLogPath},
+		capture:    []string{SourceStdout},
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+	cfg.fromEnv()
+
 	wa := &Watcher{
-		interval:    interval,
-		descColour:  GreenBold,
-		valueColour: BlueBold,
-		logColour:   WhiteBold,
-		b:           make([]byte, 1024),
-		c:           make(chan struct{}, 1),
-	}
-	go wa.render()
+		interval:   cfg.interval,
+		c:          make(chan struct{}, 1),
+		theme:      cfg.theme,
+		colourMode: cfg.colourMode,
+		hight:      cfg.hight,
+		width:      cfg.width,
+		whHardSet:  cfg.whHardSet,
+		timeFormat: cfg.timestamps,
+	}
+	wa.applyTheme()
+	wa.SetLog(cfg.log)
+	if cfg.output == OutputOff {
+		disableBreakpoints()
+		return wa
+	}
+
+	// streams are swapped before returning so nothing printed after Create misses the log pane
+	out := os.Stdout
+	if cfg.captures(SourceStdout) {
+		r, w, err := os.Pipe()
+		if err == nil {
+			os.Stdout = w
+			go wa.read(r, wa.Writer(SourceStdout))
+		}
+	}
+	if cfg.captures(SourceStderr) {
+		wa.CaptureStderr()
+	}
+	if cfg.interactive {
+		wa.Interactive()
+	}
+	go wa.render(out)
 	return wa
 }
 
-func (wa *Watcher) render() {
-	defer logFile.Close()
+// render draws the dashboard on oldStdout, the terminal stdout was before it got captured.
+func (wa *Watcher) render(oldStdout *os.File) {
 	var wSize *unix.Winsize = &unix.Winsize{
 		Row: wa.hight,
 		Col: wa.width,
 	}
 	var out string
-	var sl []string
-	var combinedLen int
 	var err error
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	go wa.read(r)
-
-	os.Stdout = w
 	for {
 		if !wa.whHardSet {
 			wSize, err = unix.IoctlGetWinsize(int(oldStdout.Fd()), unix.TIOCGWINSZ)
@@ -92,30 +151,30 @@ func (wa *Watcher) render() {
 			}
 		}
 
-		// using fmt.Fprintf has worse performance
-		// so we are buffering the output and then writing it to the terminal in one go
-		out = ""
-		for v := range vars.Iter() {
-			out += fmt.Sprintf("%s%s%s%v\n", wa.descColour, v.Key, wa.valueColour, reflect.Indirect(reflect.ValueOf(v.Value)))
+		if hasBreakpoints() {
+			wa.Interactive()
 		}
 
-		for v := range funcs.Iter() {
-			out += fmt.Sprintf("%s%s%s%v\n", wa.descColour, v.Key, wa.valueColour, v.Value())
-		}
-		for i := 0; i < int(wSize.Col); i++ {
-			out += "-"
-		}
-
-		sl = strings.Split(wa.buff, "\n")
-		combinedLen = vars.Len() + funcs.Len()
-		if len(sl)+combinedLen > int(wSize.Row) {
-			wa.buff = strings.Join(sl[len(sl)-int(wSize.Row)+combinedLen:], "\n")
-			out += fmt.Sprintf("%s%s", wa.logColour, wa.buff)
+		noColour := wa.frameColours()
+		wa.cols = int(wSize.Col)
+		if v := wa.currentView(); v != nil {
+			lines := v.lines(wa, int(wSize.Col), int(wSize.Row))
+			for i, l := range lines {
+				lines[i] = truncate(l, wa.cols) + Reset
+			}
+			out = strings.Join(lines, "\n")
 		} else {
-			out += fmt.Sprintf("%s%s", wa.logColour, wa.buff)
+			out = wa.dashboard(int(wSize.Row))
+		}
+		if noColour {
+			out = stripColours(out)
 		}
 		oldStdout.Write([]byte("\033[H\033[2J"))
 		oldStdout.Write([]byte(out))
+		if wa.bell {
+			oldStdout.Write([]byte("\a"))
+			wa.bell = false
+		}
 		// rerenders the screen after the interval or after new data is received
 		// I don't know if this is the best idea ¯\_(ツ)_/¯
 		select {
@@ -125,29 +184,131 @@ func (wa *Watcher) render() {
 	}
 }
 
-func (wa *Watcher) read(r *os.File) {
-	for {
-		i, _ := r.Read(wa.b)
-		wa.buff += string(wa.b[:i])
-		logFile.Write(wa.b[:i])
-		wa.c <- struct{}{}
+// dashboard draws the entries, panels and as much of the log as fits in rows.
+func (wa *Watcher) dashboard(rows int) string {
+	// using fmt.Fprintf has worse performance
+	// so we are buffering the output and then writing it to the terminal in one go
+	out := ""
+	now := time.Now()
+	if reason, paused := breakStatus(); paused {
+		out += truncate(fmt.Sprintf("%s%s PAUSED at %s  [c]ontinue [s]tep ", wa.alertColour, "\033[7m", reason), wa.cols) + Reset + "\n"
+	}
+	prompt, selected := wa.editStatus()
+	if prompt != "" {
+		out += truncate(wa.promptColour+prompt, wa.cols) + Reset + "\n"
+	}
+	for v := range vars.Iter() {
+		out += wa.line(v.Key, v.Value, now, v.Key == selected)
+	}
+
+	for v := range funcs.Iter() {
+		out += wa.line(v.Key, v.Value, now, false)
+	}
+
+	for v := range panels.Iter() {
+		out += wa.panel(v.Key, v.Value)
+	}
+	out += wa.borderColour
+	for i := 0; i < wa.cols; i++ {
+		out += "-"
+	}
+	out += Reset
+
+	rows -= strings.Count(out, "\n") + 1
+	if rows < 1 {
+		// when the entries alone don't fit only the last line of the log is kept
+		rows = 1
+	}
+	return out + "\n" + strings.Join(wa.logPane(rows), "\n")
+}
+
+// log appends a line from peek itself to the log pane and the log file.
+func (wa *Watcher) log(s string) {
+	wa.addLine(SourcePeek, s)
+	wa.notify()
+}
+
+// notify triggers a rerender without waiting for the interval.
+func (wa *Watcher) notify() {
+	select {
+	case wa.c <- struct{}{}:
+	default:
 	}
 }
 
+// line renders a single entry, applying its rules.
+func (wa *Watcher) line(desc string, e *entry, now time.Time, selected bool) string {
+	v := e.get()
+	e.updateRate(v, now)
+	colour := ""
+	flash := false
+	for _, r := range e.rules {
+		// every rule is checked so all of the alerts fire, first colour wins
+		if !r.check(wa, desc, v, e.currentRate(), now) {
+			continue
+		}
+		if colour == "" {
+			colour = r.colour
+			if r.alert {
+				colour = wa.alertColour
+			}
+		}
+		flash = flash || r.flash
+	}
+	if colour == "" {
+		colour = wa.valueColour
+	}
+	if flash && now.UnixMilli()/500%2 == 0 {
+		colour += "\033[7m"
+	}
+	descColour := wa.descColour
+	if selected {
+		descColour += "\033[7m"
+	}
+	status := ""
+	if e.probe != nil {
+		status = e.probe.status(wa)
+	}
+	var text string
+	if e.draw != nil {
+		text = e.draw(e, v, wa.cols-displayWidth(desc))
+	} else {
+		text = e.text(v) + e.extras(wa)
+	}
+	return truncate(fmt.Sprintf("%s%s%s%s%s%s", descColour, desc, Reset, colour, text, status), wa.cols) + Reset + "\n"
+}
+
 // Add adds a variable to the watcher.
 // description is a string that will be printed before the variable.
 // Variable can be any type in 'constraints.ordered' interface.
-func Var[T constraints.Ordered](desc string, v *T) {
-	vars.Set(desc, v)
+// opts can be used to attach rules, see Rule, to guard the variable with a lock, see Locked,
+// or to format the value, e.g. peek.Var("mem: ", &n, peek.Bytes()).
+func Var[T constraints.Ordered](desc string, v *T, opts ...EntryOption) {
+	e := &entry{read: func() any { return *v }}
+	e.get = func() any {
+		if e.locker != nil {
+			e.locker.Lock()
+			defer e.locker.Unlock()
+		}
+		return e.read()
+	}
+	e.set = setter(e, v)
+	vars.Set(desc, e.with(opts))
 }
 
 // Add adds a func to the watcher which will be run each on itteration.
 // description is a string that will be printed before the variable returned by func.
-func Func(desc string, v func() any) {
-	funcs.Set(desc, v)
+// The func runs with a timeout and its panics are recovered, see Timeout.
+// Expensive funcs can be sampled less often or in the background, see Every and Async.
+// opts can be used to attach rules, see Rule, or to format the value, see Format.
+func Func(desc string, v func() any, opts ...EntryOption) {
+	p := &probe{fn: v, timeout: DefaultTimeout}
+	e := &entry{get: p.get, probe: p}
+	funcs.Set(desc, e.with(opts))
 }
 
 // SetColour sets the colour of the description, value and logs.
+// Use SetTheme to change the colours of everything else too.
 func (wa *Watcher) SetColour(desc, value string, log string) {
 	wa.descColour = desc
 	wa.valueColour = value
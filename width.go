@@ -0,0 +1,158 @@
+package peek
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wide are the East Asian Wide and Fullwidth ranges plus emoji, which take two cells.
+var wide = []struct{ lo, hi rune }{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4},
+	{0x17000, 0x18AFF}, {0x1B000, 0x1B2FF}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F251}, {0x1F300, 0x1F64F},
+	{0x1F680, 0x1F6FF}, {0x1F7E0, 0x1F7EB}, {0x1F90C, 0x1F9FF}, {0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+// runeWidth returns how many terminal cells r takes.
+func runeWidth(r rune) int {
+	switch {
+	case r == '\t':
+		return 1
+	case r < 0x20, r == 0x7f, unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r < 0x1100:
+		return 1
+	}
+	for _, w := range wide {
+		if r >= w.lo && r <= w.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// escapeLen returns the length of the ANSI escape sequence at the start of s, 0 if there is none.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != 0x1b {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		// CSI ends with a byte in @-~
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		// OSC ends with BEL or ST
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
+
+// isSGR reports whether seq is an SGR sequence, the only kind that just sets colours and attributes.
+func isSGR(seq string) bool {
+	if len(seq) < 3 || seq[1] != '[' || seq[len(seq)-1] != 'm' {
+		return false
+	}
+	for _, c := range seq[2 : len(seq)-1] {
+		if (c < '0' || c > '9') && c != ';' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// displayWidth returns how many cells s takes, ignoring escape sequences.
+func displayWidth(s string) int {
+	w := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w += runeWidth(r)
+		i += size
+	}
+	return w
+}
+
+// wrap splits s into rows of at most width cells. Every row ends with a reset
+// and the next one starts with the colours that were active, so they don't bleed.
+// Escape sequences other than SGR, e.g. clearing the screen or moving the cursor, are dropped.
+func wrap(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var rows []string
+	var row, sgr strings.Builder
+	w := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			if seq := s[i : i+n]; isSGR(seq) {
+				row.WriteString(seq)
+				// a reset drops the colours collected so far
+				if seq == Reset || seq == "\033[m" {
+					sgr.Reset()
+				} else {
+					sgr.WriteString(seq)
+				}
+			}
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := runeWidth(r)
+		if w+rw > width && w > 0 {
+			rows = append(rows, row.String()+Reset)
+			row.Reset()
+			row.WriteString(sgr.String())
+			w = 0
+		}
+		// tabs and control characters would move the cursor by an unknown amount
+		switch {
+		case r == '\t':
+			row.WriteByte(' ')
+		case r < 0x20, r == 0x7f:
+		default:
+			row.WriteString(s[i : i+size])
+		}
+		w += rw
+		i += size
+	}
+	return append(rows, row.String()+Reset)
+}
+
+// truncate cuts s to width cells, marking the cut with an ellipsis.
+// Like wrap it drops escape sequences other than SGR.
+func truncate(s string, width int) string {
+	if displayWidth(s) <= width {
+		return strings.TrimSuffix(wrap(s, width)[0], Reset)
+	}
+	rows := wrap(s, width-1)
+	return strings.TrimSuffix(rows[0], Reset) + "…" + Reset
+}
@@ -0,0 +1,54 @@
+package peek
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want int
+	}{
+		{"abc", 3},
+		{"日本", 4},
+		{"é", 1},
+		{"🚀x", 3},
+		{Red + "ab" + Reset, 2},
+		{"a\033]0;title\ab", 2},
+	} {
+		if got := displayWidth(tc.s); got != tc.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	for _, tc := range []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"abcdef", 4, []string{"abcd" + Reset, "ef" + Reset}},
+		{"日本語", 4, []string{"日本" + Reset, "語" + Reset}},
+		{Red + "abcd" + Reset + "ef", 3, []string{Red + "abc" + Reset, Red + "d" + Reset + "ef" + Reset}},
+		{"a\tb\x07", 80, []string{"a b" + Reset}},
+		{"a\033[2Jb\033[5;1Hc\033]0;title\ad", 80, []string{"abcd" + Reset}},
+	} {
+		if got := wrap(tc.s, tc.width); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("wrap(%q, %d) = %q, want %q", tc.s, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got, want := truncate("abcdef", 4), "abc…"+Reset; got != want {
+		t.Errorf("truncate = %q, want %q", got, want)
+	}
+	if got := truncate("abc", 4); got != "abc" {
+		t.Errorf("truncate = %q, want %q", got, "abc")
+	}
+	if got := truncate("a\033[2Jb", 4); got != "ab" {
+		t.Errorf("truncate = %q, want %q", got, "ab")
+	}
+}